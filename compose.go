@@ -0,0 +1,105 @@
+package trixie
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/donutloop/trixie/middleware"
+)
+
+// chainKey identifies the handler of a route for a method
+type chainKey struct {
+	route  RouteInterface
+	method string
+}
+
+// chain is a handler wrapped by the middleware stack, with the revision of the
+// handlers of the route it was composed for
+type chain struct {
+	handler  http.Handler
+	revision uint64
+}
+
+// revisioned is a route which counts the changes of its handlers, see Route.
+// The handlers of other routes are composed per request, as it can't be told
+// whether they were replaced.
+type revisioned interface {
+	handlersRevision() uint64
+}
+
+// chains holds the handlers of the routes wrapped by the middleware stack of the router.
+// They are composed when a route is registered and recomposed when the stack or the
+// handlers of the route change, so the constructors of the middleware run once per
+// route and method.
+type chains struct {
+	mu       sync.RWMutex
+	handlers map[chainKey]chain
+}
+
+// compose wraps the handlers of the route for all its methods
+func (c *chains) compose(route RouteInterface, middlewares []middleware.Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rv, ok := route.(revisioned)
+	if !ok {
+		return
+	}
+
+	if c.handlers == nil {
+		c.handlers = map[chainKey]chain{}
+	}
+
+	for method, handler := range route.GetHandlers() {
+		c.handlers[chainKey{route: route, method: method}] = chain{
+			handler:  middleware.Stack(middlewares...).Then(handler),
+			revision: rv.handlersRevision(),
+		}
+	}
+}
+
+// recompose wraps the handlers of all composed routes again
+func (c *chains) recompose(middlewares []middleware.Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.handlers {
+		c.handlers[key] = chain{
+			handler:  middleware.Stack(middlewares...).Then(key.route.GetHandler(key.method)),
+			revision: key.route.(revisioned).handlersRevision(),
+		}
+	}
+}
+
+// handler returns the composed handler of the route for the method.
+// A handler which was added or replaced after the registration of the route
+// is composed on demand.
+func (c *chains) handler(route RouteInterface, method string, middlewares []middleware.Middleware) http.Handler {
+	rv, ok := route.(revisioned)
+	if !ok {
+		return middleware.Stack(middlewares...).Then(route.GetHandler(method))
+	}
+
+	key := chainKey{route: route, method: method}
+	revision := rv.handlersRevision()
+
+	c.mu.RLock()
+	ch, found := c.handlers[key]
+	c.mu.RUnlock()
+
+	if found && ch.revision == revision {
+		return ch.handler
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.handlers == nil {
+		c.handlers = map[chainKey]chain{}
+	}
+
+	ch = chain{handler: middleware.Stack(middlewares...).Then(route.GetHandler(method)), revision: revision}
+	c.handlers[key] = ch
+
+	return ch.handler
+}
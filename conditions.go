@@ -0,0 +1,132 @@
+package trixie
+
+import (
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// RequestMatcher is a condition a request has to fulfil to match a route,
+// besides path and method.
+//
+// Conditions are evaluated after the path lookup to choose among routes which
+// share a pattern. If no route fulfils its conditions, the lookup falls through
+// to the routes without host and finally to the not found handler.
+type RequestMatcher interface {
+	Match(req *http.Request) bool
+}
+
+// RequestMatcherFunc is an adapter to use functions as request matcher.
+type RequestMatcherFunc func(req *http.Request) bool
+
+func (f RequestMatcherFunc) Match(req *http.Request) bool { return f(req) }
+
+// headerMatcher matches the value of a header, an empty value only requires the header
+type headerMatcher struct {
+	key   string
+	value string
+}
+
+func (hm headerMatcher) Match(req *http.Request) bool {
+	values, found := req.Header[hm.key]
+	if !found {
+		return false
+	}
+
+	if hm.value == "" {
+		return true
+	}
+
+	for _, value := range values {
+		if value == hm.value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// headerRegexpMatcher matches the value of a header against a regular expression
+type headerRegexpMatcher struct {
+	key    string
+	regexp *regexp.Regexp
+}
+
+func (hm headerRegexpMatcher) Match(req *http.Request) bool {
+	for _, value := range req.Header[hm.key] {
+		if hm.regexp.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryMatcher matches the value of a query parameter, an empty value only requires the parameter
+type queryMatcher struct {
+	key   string
+	value string
+}
+
+func (qm queryMatcher) Match(req *http.Request) bool {
+	values, found := req.URL.Query()[qm.key]
+	if !found {
+		return false
+	}
+
+	if qm.value == "" {
+		return true
+	}
+
+	for _, value := range values {
+		if value == qm.value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// schemeMatcher matches the scheme of the request, http or https
+type schemeMatcher []string
+
+func (sm schemeMatcher) Match(req *http.Request) bool {
+	scheme := requestScheme(req)
+	for _, s := range sm {
+		if strings.EqualFold(s, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestScheme returns the scheme of the request, a server request has no scheme in its URL.
+func requestScheme(req *http.Request) string {
+	if req.URL.Scheme != "" {
+		return req.URL.Scheme
+	}
+
+	if req.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
+
+// contentTypeMatcher matches the media type of the request body, parameters like charset are ignored
+type contentTypeMatcher []string
+
+func (cm contentTypeMatcher) Match(req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	for _, contentType := range cm {
+		if strings.EqualFold(contentType, mediaType) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,69 @@
+package trixie
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouteConditions(t *testing.T) {
+	router := Classic()
+
+	router.Get("/items", namedHandler("default"))
+	router.Get("/items", namedHandler("v2")).(*Route).MatchHeader("X-API-Version", "2")
+	router.Get("/items", namedHandler("v3")).(*Route).MatchHeaderRegexp("x-api-version", "^3(\\.[0-9]+)?$")
+	router.Get("/items", namedHandler("csv")).(*Route).MatchQuery("format", "csv")
+	router.Post("/items", namedHandler("json")).(*Route).MatchContentType("application/json")
+	router.Get("/admin", namedHandler("admin")).(*Route).MatchScheme("https")
+	router.Get("/export", namedHandler("export")).(*Route).MatchQuery("token", "")
+
+	testCases := []struct {
+		method     string
+		url        string
+		header     http.Header
+		tls        bool
+		statusCode int
+		body       string
+	}{
+		{method: http.MethodGet, url: "/items", statusCode: http.StatusOK, body: "default"},
+		{method: http.MethodGet, url: "/items", header: http.Header{"X-Api-Version": {"2"}}, statusCode: http.StatusOK, body: "v2"},
+		{method: http.MethodGet, url: "/items", header: http.Header{"X-Api-Version": {"3.1"}}, statusCode: http.StatusOK, body: "v3"},
+		{method: http.MethodGet, url: "/items", header: http.Header{"X-Api-Version": {"4"}}, statusCode: http.StatusOK, body: "default"},
+		{method: http.MethodGet, url: "/items?format=csv", statusCode: http.StatusOK, body: "csv"},
+		{method: http.MethodPost, url: "/items", header: http.Header{"Content-Type": {"application/json; charset=utf-8"}}, statusCode: http.StatusOK, body: "json"},
+		{method: http.MethodPost, url: "/items", header: http.Header{"Content-Type": {"text/plain"}}, statusCode: http.StatusNotFound},
+		{method: http.MethodGet, url: "/admin", statusCode: http.StatusNotFound},
+		{method: http.MethodGet, url: "/admin", tls: true, statusCode: http.StatusOK, body: "admin"},
+		{method: http.MethodGet, url: "/export?token", statusCode: http.StatusOK, body: "export"},
+		{method: http.MethodGet, url: "/export", statusCode: http.StatusNotFound},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(testCase.method, testCase.url, nil)
+		for key, values := range testCase.header {
+			req.Header[key] = values
+		}
+		if testCase.tls {
+			req.TLS = &tls.ConnectionState{}
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != testCase.statusCode {
+			t.Errorf("%s %s: Unexpected status code (Expected: %d, Actual: %d)", testCase.method, testCase.url, testCase.statusCode, rec.Code)
+			continue
+		}
+
+		if testCase.body != "" && rec.Body.String() != testCase.body {
+			t.Errorf("%s %s: Unexpected body (Expected: %s, Actual: %s)", testCase.method, testCase.url, testCase.body, rec.Body.String())
+		}
+	}
+
+	// without request the conditions are ignored
+	match, err := router.Lookup(http.MethodGet, "/admin")
+	if err != nil || match.Pattern != "/admin" {
+		t.Errorf("Unexpected lookup of /admin (%v)", err)
+	}
+}
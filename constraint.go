@@ -0,0 +1,146 @@
+package trixie
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// constrainedSegment is a segment type narrowed by a constraint:
+//
+//	{id:number(1..999999)}    the value is a number between 1 and 999999
+//	{page:number(1..)}        the value is a number of at least 1
+//	{code:string(len=3)}      the value has exactly 3 characters
+//	{name:slug(len=3..20)}    the value has 3 to 20 characters
+//	{lang:in(en,de,fr)}       the value is one of en, de and fr
+//
+// Constraints are separated by comma, except the values of in.
+// A numeric range only accepts plain integers like 42 or -1, not 1.5, 1e3 or +5.
+// A constrained type is tried before the type without constraint.
+type constrainedSegment struct {
+	SegmentMatcher
+	checks []func(string) bool
+}
+
+func (cs constrainedSegment) Match(seg string) bool {
+	if !cs.SegmentMatcher.Match(seg) {
+		return false
+	}
+
+	for _, check := range cs.checks {
+		if !check(seg) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (cs constrainedSegment) Priority() int { return cs.SegmentMatcher.Priority() + 1 }
+
+// Convert converts the value with the converter of the segment type, if there is one.
+func (cs constrainedSegment) Convert(seg string) (interface{}, error) {
+	if converter, ok := cs.SegmentMatcher.(SegmentConverter); ok {
+		return converter.Convert(seg)
+	}
+	return seg, nil
+}
+
+// splitKind splits a segment type like number(1..999999) into the name and
+// the arguments of its constraint.
+func splitKind(kind string) (name, args string, constrained bool) {
+	i := strings.Index(kind, "(")
+	if i < 0 || !strings.HasSuffix(kind, ")") {
+		return kind, "", false
+	}
+	return kind[:i], kind[i+1 : len(kind)-1], true
+}
+
+// parseConstraint returns the checks of the constraint of a segment type
+func parseConstraint(name, args string) ([]func(string) bool, error) {
+	if name == "in" {
+		values := map[string]struct{}{}
+		for _, value := range strings.Split(args, ",") {
+			if value = strings.TrimSpace(value); value == "" {
+				return nil, errors.New("in has an empty value")
+			}
+			values[value] = struct{}{}
+		}
+
+		return []func(string) bool{func(seg string) bool {
+			_, found := values[seg]
+			return found
+		}}, nil
+	}
+
+	checks := make([]func(string) bool, 0)
+	for _, constraint := range strings.Split(args, ",") {
+		constraint = strings.TrimSpace(constraint)
+
+		switch {
+		case strings.HasPrefix(constraint, "len="):
+			min, max, err := parseRange(constraint[len("len="):])
+			if err != nil {
+				return nil, err
+			}
+
+			checks = append(checks, func(seg string) bool {
+				n := float64(utf8.RuneCountInString(seg))
+				return n >= min && n <= max
+			})
+		case strings.Contains(constraint, ".."):
+			min, max, err := parseRange(constraint)
+			if err != nil {
+				return nil, err
+			}
+
+			checks = append(checks, func(seg string) bool {
+				// only plain integers, ParseInt alone would accept a sign like +5
+				if !integer.MatchString(seg) {
+					return false
+				}
+				n, err := strconv.ParseInt(seg, 10, 64)
+				return err == nil && float64(n) >= min && float64(n) <= max
+			})
+		default:
+			return nil, errors.New("unknown constraint " + constraint)
+		}
+	}
+
+	return checks, nil
+}
+
+// integer matches the segments a numeric range is checked for, e.g. 42 or -1
+var integer = regexp.MustCompile(`^-?[0-9]+$`)
+
+// parseRange parses min..max, a missing bound is unlimited and
+// a single value is a range of its own.
+func parseRange(s string) (min, max float64, err error) {
+	bounds := strings.SplitN(s, "..", 2)
+	if len(bounds) == 1 {
+		bounds = append(bounds, bounds[0])
+	}
+
+	min, max = math.Inf(-1), math.Inf(1)
+
+	if bounds[0] != "" {
+		if min, err = strconv.ParseFloat(bounds[0], 64); err != nil {
+			return 0, 0, errors.New("range " + s + " has an invalid minimum")
+		}
+	}
+
+	if bounds[1] != "" {
+		if max, err = strconv.ParseFloat(bounds[1], 64); err != nil {
+			return 0, 0, errors.New("range " + s + " has an invalid maximum")
+		}
+	}
+
+	if min > max {
+		return 0, 0, errors.New("range " + s + " is empty")
+	}
+
+	return min, max, nil
+}
@@ -0,0 +1,103 @@
+package trixie
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSegmentConstraints(t *testing.T) {
+	segmentTypes := NewSegmentTypes()
+
+	testCases := []struct {
+		kind  string
+		valid []string
+		bad   []string
+	}{
+		{kind: "number(1..999999)", valid: []string{"1", "999999"}, bad: []string{"0", "1000000", "a1", "1.5", "1e3", "0x10p0", "+5", " 5"}},
+		{kind: "number(10..)", valid: []string{"10", "12345"}, bad: []string{"9"}},
+		{kind: "string(len=3)", valid: []string{"abc"}, bad: []string{"ab", "abcd"}},
+		{kind: "slug(len=2..4)", valid: []string{"go", "go-1"}, bad: []string{"g", "golang"}},
+		{kind: "in(en,de,fr)", valid: []string{"en", "fr"}, bad: []string{"es", "EN", ""}},
+		{kind: "int64(..0, len=2)", valid: []string{"-1"}, bad: []string{"1", "-10"}},
+	}
+
+	for _, testCase := range testCases {
+		matcher := segmentTypes.matcher(testCase.kind)
+		if matcher == nil {
+			t.Errorf("Unexpected missing matcher for %s", testCase.kind)
+			continue
+		}
+
+		for _, seg := range testCase.valid {
+			if !matcher.Match(seg) {
+				t.Errorf("Unexpected mismatch of %s (Type: %s)", seg, testCase.kind)
+			}
+		}
+
+		for _, seg := range testCase.bad {
+			if matcher.Match(seg) {
+				t.Errorf("Unexpected match of %s (Type: %s)", seg, testCase.kind)
+			}
+		}
+	}
+}
+
+func TestRouterSegmentConstraints(t *testing.T) {
+	router := Classic()
+
+	var pattern string
+	var values map[string]interface{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		pattern = r.Pattern
+		values = GetRouteValues(r)
+	}
+
+	router.Get("/posts/{id:int64(1..999999)}", handler)
+	router.Get("/posts/{name}", handler)
+	router.Get("/docs/{lang:in(en,de,fr)}", handler)
+	router.Get("/codes/{code:string(len=3)}/{page:int64(1..)=1}", handler)
+
+	testCases := []struct {
+		path    string
+		pattern string
+	}{
+		{path: "/posts/42", pattern: "/posts/{id:int64(1..999999)}"},
+		{path: "/posts/0", pattern: "/posts/{name}"},
+		{path: "/posts/1000000", pattern: "/posts/{name}"},
+		{path: "/posts/1.5", pattern: "/posts/{name}"},
+		{path: "/posts/1e3", pattern: "/posts/{name}"},
+		{path: "/posts/0x10p0", pattern: "/posts/{name}"},
+		{path: "/posts/+5", pattern: "/posts/{name}"},
+		{path: "/docs/de", pattern: "/docs/{lang:in(en,de,fr)}"},
+		{path: "/docs/es", pattern: ""},
+		{path: "/codes/abc", pattern: "/codes/{code:string(len=3)}/{page:int64(1..)=1}"},
+		{path: "/codes/abcd", pattern: ""},
+		{path: "/codes/abc/0", pattern: ""},
+	}
+
+	for _, testCase := range testCases {
+		pattern = ""
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, testCase.path, nil))
+
+		if pattern != testCase.pattern {
+			t.Errorf("%s: Unexpected route (Expected: %q, Actual: %q)", testCase.path, testCase.pattern, pattern)
+		}
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts/42", nil))
+	if values["id"] != int64(42) {
+		t.Errorf("Unexpected converted id (%v)", values["id"])
+	}
+
+	for _, pattern := range []string{"/a/{id:number(9..1)}", "/a/{id:number(x..)}", "/a/{id:number(5)}", "/a/{lang:in}", "/a/{lang:in(en,)}", "/a/{id:number(len=x)}"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for pattern %s", pattern)
+				}
+			}()
+			router.Get(pattern, handler)
+		}()
+	}
+}
@@ -2,6 +2,7 @@ package trixie
 
 import (
 	"context"
+	"fmt"
 	"github.com/donutloop/trixie/middleware"
 	"net/http"
 )
@@ -13,6 +14,15 @@ const (
 	paramKey                         = "paramKey"
 )
 
+// contextKey is the type of context keys which are only used by the router,
+// so they can't collide with keys of other packages.
+type contextKey string
+
+const (
+	suggestionsKey contextKey = "suggestionsKey"
+	valuesKey      contextKey = "valuesKey"
+)
+
 // GetQueries returns the query variables for the current request.
 func GetQueries(r *http.Request) *middleware.Queries {
 	if value := r.Context().Value(queriesKey); value != nil {
@@ -34,6 +44,13 @@ func GetCurrentRoute(r *http.Request) RouteInterface {
 	return nil
 }
 
+// GetRequestID returns the ID of the current request.
+// This only works when the request is wrapped by the middleware.RequestID middleware,
+// otherwise the ID is empty
+func GetRequestID(r *http.Request) string {
+	return middleware.GetRequestID(r)
+}
+
 // AddCurrentRoute adds a route instance to the current request context
 func AddCurrentRoute(r *http.Request, route RouteInterface) *http.Request {
 	return r.WithContext(context.WithValue(r.Context(), routeKey, route))
@@ -47,10 +64,91 @@ func AddRouteParameters(r *http.Request, params map[string]string) *http.Request
 // GetRouteParameter returns the parameters of route for a given request
 // This only works when called inside the handler of the matched route
 // because the matched route is stored in the request context which is cleared
-// after the handler returns
+// after the handler returns. Pre-routing middleware gets the parameters of
+// the match after the router is done (see middleware.Match)
 func GetRouteParameters(r *http.Request) map[string]string {
 	if rv := r.Context().Value(paramKey); rv != nil {
 		return rv.(map[string]string)
 	}
+	if match := middleware.GetMatch(r); match != nil {
+		return match.Params
+	}
 	return nil
 }
+
+// addPathValues sets the parameters of the match as path values (see http.Request.PathValue)
+// and the pattern of the matched route, so handlers written for http.ServeMux work as well.
+// The request is expected to be a copy made by AddRouteParameters.
+func addPathValues(r *http.Request, match *RouteMatch) *http.Request {
+	for name, value := range match.Params {
+		r.SetPathValue(name, value)
+	}
+	r.Pattern = match.Pattern
+	return r
+}
+
+// routeValues converts the parameters of a match on demand
+type routeValues struct {
+	segmentTypes *SegmentTypes
+	match        *RouteMatch
+}
+
+func addRouteValues(r *http.Request, segmentTypes *SegmentTypes, match *RouteMatch) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), valuesKey, &routeValues{segmentTypes: segmentTypes, match: match}))
+}
+
+// GetRouteValues returns the values of the typed param segments for a given request,
+// converted by their segment type (e.g. an int64 for /user/{id:int64}).
+// Values of types which don't implement SegmentConverter are kept as string.
+// This only works when called inside the handler of the matched route
+func GetRouteValues(r *http.Request) map[string]interface{} {
+	rv, ok := r.Context().Value(valuesKey).(*routeValues)
+	if !ok {
+		return nil
+	}
+
+	values := map[string]interface{}{}
+	for key, seg := range splitSegments(fullPattern(rv.match.Pattern)) {
+		typ, name, kind := parseSegment(seg)
+
+		switch typ {
+		case paramNode:
+			if kind == "" {
+				continue
+			}
+
+			// absent optional segments are only known by name, with their default
+			segKey := fmt.Sprintf("seg%d", key)
+			if value, found := rv.match.Params[segKey]; found {
+				rv.convert(values, segKey, kind, value)
+			}
+			if value, found := rv.match.Params[name]; found && name != "" {
+				rv.convert(values, name, kind, value)
+			}
+		case mixedNode:
+			parts, _ := parseMixedSegment(seg)
+			for _, part := range parts {
+				if value, found := rv.match.Params[part.name]; found && part.capture && part.kind != "" {
+					rv.convert(values, part.name, part.kind, value)
+				}
+			}
+		}
+	}
+
+	return values
+}
+
+// convert stores the converted value under key, values which can't be converted are skipped
+func (rv *routeValues) convert(values map[string]interface{}, key, kind, value string) {
+	matcher := rv.segmentTypes.matcher(kind)
+
+	converter, ok := matcher.(SegmentConverter)
+	if !ok {
+		values[key] = value
+		return
+	}
+
+	if v, err := converter.Convert(value); err == nil {
+		values[key] = v
+	}
+}
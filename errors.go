@@ -1,6 +1,9 @@
 package trixie
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // BadPathError creates error for bad path
 type BadPathError struct {
@@ -23,3 +26,38 @@ func (bme *BadMethodError) Error() string { return fmt.Sprintf("Method not vaild
 func NewBadMethodError() error {
 	return new(BadMethodError)
 }
+
+// RouteConflictError creates error for routes which can't share a node of the tree
+type RouteConflictError struct {
+	pattern string
+	other   string
+}
+
+func (rce *RouteConflictError) Error() string {
+	return fmt.Sprintf("Pattern %s conflicts with %s", rce.pattern, rce.other)
+}
+
+// NewRouteConflictError returns an error for two conflicting patterns.
+func NewRouteConflictError(pattern, other string) error {
+	return &RouteConflictError{pattern: pattern, other: other}
+}
+
+// ErrHostAfterRegistration is the reason of the panic when the host of a registered
+// route is changed, the route would silently keep matching its former hosts.
+// The host has to be set before, e.g. in the callback of Router.Path.
+var ErrHostAfterRegistration = errors.New("host of a registered route can't be changed, set it in Router.Path")
+
+// ErrNotFound is returned by a lookup when no route matches the path.
+var ErrNotFound = errors.New("no route matches the path")
+
+// ErrMethodNotAllowed is returned by a lookup when a route matches the path
+// but has no handler for the method.
+var ErrMethodNotAllowed = errors.New("method is not allowed for the path")
+
+// ErrNotAcceptable is returned by a lookup when routes match path and method
+// but none of them serves a version or produces a media type the request accepts.
+var ErrNotAcceptable = errors.New("no acceptable version for the path")
+
+// ErrUnsupportedMediaType is returned by a lookup when routes match path and method
+// but none of them consumes the media type of the request body.
+var ErrUnsupportedMediaType = errors.New("unsupported media type for the path")
@@ -0,0 +1,143 @@
+package trixie
+
+import (
+	"net/http"
+	"strings"
+)
+
+// hostTree holds the routes which are bound to a host.
+//
+// The host is a pattern of dot separated labels, labels can be parameters
+// like {tenant}.example.com or mixed like api-{region}.example.com.
+// The values of the parameters are merged into the route parameters.
+type hostTree struct {
+	host string
+	// labels of the host pattern
+	labels []*Node
+	// port of the host pattern, it's empty if the pattern has none
+	port string
+	tree RouteTreeInterface
+}
+
+func newHostTree(host string, tree RouteTreeInterface, segmentTypes *SegmentTypes) *hostTree {
+	ht := &hostTree{host: host, tree: tree}
+
+	name, port := splitHostPort(host)
+	ht.port = port
+
+	for _, label := range strings.Split(name, ".") {
+		if NodeOfType(label) == staticNode {
+			label = strings.ToLower(label)
+		}
+		ht.labels = append(ht.labels, initNode(new(Node), label, segmentTypes))
+	}
+
+	return ht
+}
+
+// specificity of the host pattern, hosts with more static labels are tried first
+// and mixed labels like api-{region} are tried before params.
+func (ht *hostTree) specificity() int {
+	specificity := 0
+	for _, n := range ht.labels {
+		switch n.typ {
+		case staticNode:
+			specificity += 2
+		case mixedNode:
+			specificity++
+		}
+	}
+	return specificity
+}
+
+// match reports whether the host and port of a request match the pattern
+// and returns the parameters of the host.
+func (ht *hostTree) match(host, port string, matchPort bool) (map[string]string, bool) {
+	if matchPort && ht.port != port {
+		return nil, false
+	}
+
+	labels := strings.Split(host, ".")
+	if len(labels) != len(ht.labels) {
+		return nil, false
+	}
+
+	var params map[string]string
+	for i, n := range ht.labels {
+		if !n.match(labels[i]) {
+			return nil, false
+		}
+
+		switch n.typ {
+		case paramNode:
+			if n.name == "" {
+				continue
+			}
+			if params == nil {
+				params = map[string]string{}
+			}
+			params[n.name] = labels[i]
+		case mixedNode:
+			names := make([]string, 0, len(n.parts))
+			for _, part := range n.parts {
+				if part.capture {
+					names = append(names, part.name)
+				}
+			}
+
+			captures := make([]string, len(names))
+			matchParts(n.parts, labels[i], captures)
+			if params == nil {
+				params = map[string]string{}
+			}
+			for k, name := range names {
+				params[name] = captures[k]
+			}
+		}
+	}
+
+	return params, true
+}
+
+// requestHost returns the host of the request in lower case, including the port
+func requestHost(req *http.Request) string {
+	return strings.ToLower(req.Host)
+}
+
+// splitHostPort splits a host or host pattern into host and port, the port is empty if there is none.
+// Unlike net.SplitHostPort it ignores the colon of a typed label like {region:in(eu,us)}.
+func splitHostPort(host string) (string, string) {
+	i := strings.LastIndex(host, ":")
+	if i < 0 || i < strings.LastIndex(host, "}") || i < strings.LastIndex(host, "]") {
+		return host, ""
+	}
+	return strings.Trim(host[:i], "[]"), host[i+1:]
+}
+
+// treeMatch is a tree which is used to look up a request, with the parameters of the host
+type treeMatch struct {
+	tree   RouteTreeInterface
+	params map[string]string
+}
+
+// treesFor returns the trees which are used to look up a request for the host,
+// trees of routes bound to the host come before the tree of all other routes.
+func (r *Router) treesFor(host string) []treeMatch {
+	trees := make([]treeMatch, 0, 2)
+
+	if host != "" {
+		name, port := splitHostPort(host)
+
+		for _, ht := range r.hostTrees {
+			if params, ok := ht.match(name, port, r.MatchHostPort); ok {
+				trees = append(trees, treeMatch{tree: ht.tree, params: params})
+			}
+		}
+	}
+
+	if r.tree != nil {
+		trees = append(trees, treeMatch{tree: r.tree})
+	}
+
+	return trees
+}
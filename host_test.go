@@ -0,0 +1,174 @@
+package trixie
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterHosts(t *testing.T) {
+	router := Classic()
+
+	router.Path("/users/{id}", func(route RouteInterface) {
+		route.(HostRoute).SetHost("{tenant}.example.com").AddHandlerFunc(http.MethodGet, namedHandler("tenant"))
+	})
+	router.Path("/users/{id}", func(route RouteInterface) {
+		route.(HostRoute).SetHost("WWW.example.com").AddHandlerFunc(http.MethodGet, namedHandler("www"))
+	})
+	router.Path("/users/{id}", func(route RouteInterface) {
+		route.(HostRoute).SetHost("api-{region:in(eu,us)}.example.com").AddHandlerFunc(http.MethodGet, namedHandler("api"))
+	})
+	router.Get("/users/{id}", namedHandler("any"))
+
+	testCases := []struct {
+		url     string
+		pattern string
+		params  map[string]string
+	}{
+		{url: "http://acme.example.com/users/1", pattern: "tenant", params: map[string]string{"tenant": "acme", "id": "1"}},
+		{url: "http://acme.example.com:8080/users/1", pattern: "tenant", params: map[string]string{"tenant": "acme", "id": "1"}},
+		{url: "http://www.example.com/users/1", pattern: "www", params: map[string]string{"id": "1"}},
+		{url: "http://api-eu.example.com/users/1", pattern: "api", params: map[string]string{"region": "eu", "id": "1"}},
+		{url: "http://api-asia.example.com/users/1", pattern: "tenant", params: map[string]string{"tenant": "api-asia"}},
+		{url: "http://a.b.example.com/users/1", pattern: "any", params: map[string]string{"id": "1"}},
+		{url: "http://example.org/users/1", pattern: "any", params: map[string]string{"id": "1"}},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(http.MethodGet, testCase.url, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Body.String() != testCase.pattern {
+			t.Errorf("%s: Unexpected route (Expected: %s, Actual: %s)", testCase.url, testCase.pattern, rec.Body.String())
+			continue
+		}
+
+		match, err := router.Match(req)
+		if err != nil {
+			t.Errorf("%s: Unexpected error (%v)", testCase.url, err)
+			continue
+		}
+
+		for name, value := range testCase.params {
+			if match.Params[name] != value {
+				t.Errorf("%s: Unexpected parameter %s (Expected: %s, Actual: %s)", testCase.url, name, value, match.Params[name])
+			}
+		}
+	}
+
+	for _, host := range []string{"{id}.example.com", "#(a).example.com", "{rest...}.example.com", "a..example.com", "example.com:http"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for host %s", host)
+				}
+			}()
+			router.Path("/users/{id}", func(route RouteInterface) {
+				route.(HostRoute).SetHost(host).AddHandlerFunc(http.MethodGet, namedHandler("bad"))
+			})
+		}()
+	}
+}
+
+func TestRouterMatchHostPort(t *testing.T) {
+	router := Classic()
+	router.MatchHostPort = true
+
+	router.Path("/", func(route RouteInterface) {
+		route.(HostRoute).SetHost("example.com:8080").AddHandlerFunc(http.MethodGet, namedHandler("8080"))
+	})
+	router.Path("/", func(route RouteInterface) {
+		route.(HostRoute).SetHost("example.com").AddHandlerFunc(http.MethodGet, namedHandler("none"))
+	})
+
+	testCases := []struct {
+		url     string
+		pattern string
+	}{
+		{url: "http://example.com:8080/", pattern: "8080"},
+		{url: "http://example.com/", pattern: "none"},
+		{url: "http://example.com:9090/"},
+	}
+
+	for _, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, testCase.url, nil))
+
+		pattern := ""
+		if rec.Code == http.StatusOK {
+			pattern = rec.Body.String()
+		}
+
+		if pattern != testCase.pattern {
+			t.Errorf("%s: Unexpected route (Expected: %q, Actual: %q)", testCase.url, testCase.pattern, pattern)
+		}
+	}
+}
+
+func TestRouterExplainAndSuggestHosts(t *testing.T) {
+	router := Classic()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	router.Path("/admin/users/{id}", func(route RouteInterface) {
+		route.(HostRoute).SetHost("admin.example.com").AddHandlerFunc(http.MethodGet, handler)
+	})
+	router.Get("/users/{id}", handler)
+
+	trace := router.ExplainRequest(httptest.NewRequest(http.MethodGet, "http://admin.example.com/admin/users/1", nil))
+	if trace.Err != nil || trace.Route == nil || trace.Route.GetPattern() != "/admin/users/{id}" {
+		t.Errorf("Unexpected trace of a route bound to the host (Error: %v, Route: %v)", trace.Err, trace.Route)
+	}
+
+	trace = router.ExplainRequest(httptest.NewRequest(http.MethodGet, "http://www.example.com/admin/users/1", nil))
+	if trace.Err != ErrNotFound {
+		t.Errorf("Unexpected error for another host (Expected: %v, Actual: %v)", ErrNotFound, trace.Err)
+	}
+
+	if trace := router.Explain(http.MethodGet, "/USERS/1"); trace.Err != nil {
+		t.Errorf("Unexpected error of a path in upper case (%v)", trace.Err)
+	}
+
+	suggestions := router.Suggest(http.MethodGet, "/admin/user/1")
+	if len(suggestions) == 0 || suggestions[0].Pattern != "/admin/users/{id}" || suggestions[0].Host != "admin.example.com" {
+		t.Errorf("Unexpected suggestions (%+v)", suggestions)
+	}
+
+	var inHandler []Suggestion
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inHandler = GetSuggestions(r)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://admin.example.com/admin/user/1", nil))
+	if len(inHandler) == 0 || inHandler[0].Pattern != "/admin/users/{id}" {
+		t.Errorf("Unexpected suggestions for the host (%+v)", inHandler)
+	}
+
+	inHandler = nil
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://www.example.com/admin/user/1", nil))
+	for _, suggestion := range inHandler {
+		if suggestion.Host != "" {
+			t.Errorf("Unexpected suggestion of a route bound to another host (%+v)", suggestion)
+		}
+	}
+}
+
+func TestRouterHostAfterRegistration(t *testing.T) {
+	router := Classic()
+	route := router.Get("/x", func(w http.ResponseWriter, r *http.Request) {})
+
+	func() {
+		defer func() {
+			if recover() != ErrHostAfterRegistration.Error() {
+				t.Errorf("Expected a panic with %v", ErrHostAfterRegistration)
+			}
+		}()
+		route.(HostRoute).SetHost("a.example.com")
+	}()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://b.example.com/x", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Unexpected status of the unchanged route (Expected: %d, Actual: %d)", http.StatusOK, rec.Code)
+	}
+}
@@ -0,0 +1,266 @@
+package trixie
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// RouteMatch stores information about a resolved route.
+type RouteMatch struct {
+	// Route is the matched route. It's also set if the method is not allowed.
+	Route RouteInterface
+	// Handler is the handler registered for the method, nil on a miss.
+	Handler http.Handler
+	// Params are the parameters of the path, as returned by GetRouteParameters.
+	Params map[string]string
+	// Pattern is the pattern of the matched route.
+	Pattern string
+
+	// varyAccept is set if the routes of the path are bound to versions or media types
+	varyAccept bool
+}
+
+// Lookup resolves method and path against the registered routes
+// without serving anything, neither handlers nor middleware are invoked.
+//
+// The returned error is ErrNotFound if no route matches the path and
+// ErrMethodNotAllowed if a route matches the path but not the method and
+// ErrNotAcceptable if no route serves the version the request accepts (see Route.SetVersion).
+// On ErrMethodNotAllowed and ErrNotAcceptable the match still holds the route, its pattern and the params.
+// Media types (see Route.Consumes) are only checked by Match.
+// The conditions of routes (e.g. MatchHeader) aren't evaluated without request, see Match.
+func (r *Router) Lookup(method, path string) (*RouteMatch, error) {
+	return r.lookup(nil, method, "", path)
+}
+
+// lookup resolves method and path in the trees for the host (host[:port]).
+// A route which matches path and method wins over a route which only matches the path.
+// The conditions of the routes (see RequestMatcher) are only evaluated if req is set.
+func (r *Router) lookup(req *http.Request, method, host, path string) (*RouteMatch, error) {
+	miss, missErr := new(RouteMatch), ErrNotFound
+
+	for _, tm := range r.treesFor(host) {
+		route, params, err := tm.tree.Find(tm.tree.GetRoot(), path)
+		if err != nil {
+			continue
+		}
+
+		// the parameters of the host are merged into the route parameters
+		if len(tm.params) > 0 && params == nil {
+			params = make(map[string]string, len(tm.params))
+		}
+		for name, value := range tm.params {
+			params[name] = value
+		}
+
+		match, err := r.newRouteMatch(req, method, route, params)
+		if err == nil {
+			return match, nil
+		}
+
+		if missErr == ErrNotFound {
+			miss, missErr = match, err
+		}
+	}
+
+	return miss, missErr
+}
+
+// Explain looks up method and path like Lookup and records every node
+// visited in the tree, the performed comparison and its result.
+// Trace.Err is set to the error Lookup would return for the path, which is
+// normalized like ServeHTTP does it. See ExplainRequest for routes bound to a host.
+func (r *Router) Explain(method, path string) *Trace {
+	return r.explain(nil, method, "", r.normalizePath(path))
+}
+
+// ExplainRequest explains the lookup of the request like Explain,
+// routes bound to the host of the request and their conditions are taken into account like by Match.
+func (r *Router) ExplainRequest(req *http.Request) *Trace {
+	return r.explain(req, req.Method, requestHost(req), r.requestPath(req))
+}
+
+// explain traces the lookup in the trees for the host. The trace of the tree which
+// resolves the request is returned, on a miss the trace of the error Match would return.
+func (r *Router) explain(req *http.Request, method, host, path string) *Trace {
+	var miss *Trace
+
+	for _, tm := range r.treesFor(host) {
+		trace := explainTree(tm.tree, path)
+		trace.Method = method
+
+		if trace.Err != nil {
+			trace.Err = ErrNotFound
+		} else {
+			if len(tm.params) > 0 && trace.Params == nil {
+				trace.Params = make(map[string]string, len(tm.params))
+			}
+			for name, value := range tm.params {
+				trace.Params[name] = value
+			}
+
+			_, trace.Err = r.newRouteMatch(req, method, trace.Route, trace.Params)
+		}
+
+		if trace.Err == nil {
+			return trace
+		}
+
+		// the first error besides not found wins, like in lookup
+		if miss == nil || miss.Err == ErrNotFound {
+			miss = trace
+		}
+	}
+
+	if miss == nil {
+		return &Trace{Method: method, Path: path, Err: ErrNotFound}
+	}
+
+	return miss
+}
+
+// explainTree traces the lookup of the path, a tree without Explain only tells the result.
+func explainTree(tree RouteTreeInterface, path string) *Trace {
+	if et, ok := tree.(ExplainTree); ok {
+		return et.Explain(tree.GetRoot(), path)
+	}
+
+	trace := &Trace{Path: path}
+	trace.Route, trace.Params, trace.Err = tree.Find(tree.GetRoot(), path)
+	return trace
+}
+
+// newRouteMatch chooses among the routes of a leaf the first one which fulfils
+// its conditions, has a handler for the method, serves the negotiated version
+// and consumes and produces the media types of the request.
+func (r *Router) newRouteMatch(req *http.Request, method string, leaf RouteInterface, params map[string]string) (*RouteMatch, error) {
+	match := new(RouteMatch)
+
+	if leaf == nil {
+		return match, ErrNotFound
+	}
+
+	routes := make([]RouteInterface, 0)
+	for _, route := range candidates(leaf) {
+		if req == nil || matches(route, req) {
+			routes = append(routes, route)
+		}
+	}
+
+	if len(routes) == 0 {
+		return match, ErrNotFound
+	}
+
+	match.Route = routes[0]
+	match.Params = params
+	match.Pattern = routes[0].GetPattern()
+	match.varyAccept = versioned(routes) || producing(routes)
+
+	allowed := make([]RouteInterface, 0, len(routes))
+	for _, route := range routes {
+		if Methods.lookup(method) && route.HasHandler(method) {
+			allowed = append(allowed, route)
+		}
+	}
+
+	if len(allowed) == 0 {
+		return match, ErrMethodNotAllowed
+	}
+
+	if versioned(allowed) {
+		if allowed = r.negotiateVersion(req, allowed); len(allowed) == 0 {
+			return match, ErrNotAcceptable
+		}
+	}
+
+	if req == nil {
+		match.Route = allowed[0]
+		match.Handler = allowed[0].GetHandler(method)
+		return match, nil
+	}
+
+	// the media types are checked before the handler runs
+	err := error(nil)
+	for _, route := range allowed {
+		switch {
+		case !consumes(route, req):
+			if err == nil {
+				err = ErrUnsupportedMediaType
+			}
+		case !produces(route, req):
+			if err == nil {
+				err = ErrNotAcceptable
+			}
+		default:
+			match.Route = route
+			match.Handler = route.GetHandler(method)
+			return match, nil
+		}
+	}
+
+	match.Route = allowed[0]
+
+	return match, err
+}
+
+// Match resolves the request like Lookup, the path is normalized
+// the same way as ServeHTTP does it and routes bound to the host
+// of the request are taken into account.
+func (r *Router) Match(req *http.Request) (*RouteMatch, error) {
+	return r.lookup(req, req.Method, requestHost(req), r.requestPath(req))
+}
+
+// AllowedMethods returns the sorted methods of the routes which match the host
+// and the path of the request, e.g. to answer CORS preflight requests (see middleware.CORS).
+// The conditions of the routes aren't evaluated because a preflight request doesn't
+// carry the headers of the actual request. It returns nil if no route matches the path.
+func (r *Router) AllowedMethods(req *http.Request) []string {
+	path := r.requestPath(req)
+
+	seen := map[string]struct{}{}
+	for _, tm := range r.treesFor(requestHost(req)) {
+		leaf, _, err := tm.tree.Find(tm.tree.GetRoot(), path)
+		if err != nil || leaf == nil {
+			continue
+		}
+
+		for _, route := range candidates(leaf) {
+			for method := range route.GetHandlers() {
+				seen[method] = struct{}{}
+			}
+		}
+	}
+
+	if len(seen) == 0 {
+		return nil
+	}
+
+	methods := make([]string, 0, len(seen))
+	for method := range seen {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	return methods
+}
+
+// requestPath returns the path of the request which is used for the lookup.
+func (r *Router) requestPath(req *http.Request) string {
+	p := req.URL.Path
+
+	if r.UseEncodedPath {
+		p = req.URL.EscapedPath()
+	}
+
+	return r.normalizePath(p)
+}
+
+// normalizePath returns the path in lower case unless the router is case sensitive.
+func (r *Router) normalizePath(p string) string {
+	if !r.CaseSensitiveURL {
+		return strings.ToLower(p)
+	}
+
+	return p
+}
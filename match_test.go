@@ -0,0 +1,165 @@
+package trixie
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouterLookup(t *testing.T) {
+	router := Classic()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Unexpected call of handler")
+	}
+
+	router.Get("/api/user/:number", handler)
+	router.Post("/api/article/:string", handler)
+	router.Get("/api/article/:string/comments", handler)
+
+	testCases := []struct {
+		method  string
+		path    string
+		pattern string
+		err     error
+	}{
+		{
+			method:  http.MethodGet,
+			path:    "/api/user/1",
+			pattern: "/api/user/:number",
+		},
+		{
+			method:  http.MethodPost,
+			path:    "/api/article/golang",
+			pattern: "/api/article/:string",
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/api/article/golang",
+			pattern: "/api/article/:string",
+			err:     ErrMethodNotAllowed,
+		},
+		{
+			method: http.MethodGet,
+			path:   "/api/article",
+			err:    ErrNotFound,
+		},
+		{
+			method: http.MethodGet,
+			path:   "/api/comment/1",
+			err:    ErrNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		match, err := router.Lookup(testCase.method, testCase.path)
+
+		if err != testCase.err {
+			t.Errorf("Unexpected error for %s %s (Expected: %v, Actual: %v)", testCase.method, testCase.path, testCase.err, err)
+			continue
+		}
+
+		if match.Pattern != testCase.pattern {
+			t.Errorf("Unexpected pattern (Expected: %s, Actual: %s)", testCase.pattern, match.Pattern)
+		}
+
+		if err == nil && match.Handler == nil {
+			t.Errorf("Unexpected nil handler (Path: %s)", testCase.path)
+		}
+
+		if err == ErrMethodNotAllowed && match.Handler != nil {
+			t.Errorf("Unexpected non nil handler (Path: %s)", testCase.path)
+		}
+	}
+}
+
+func TestRouterMatch(t *testing.T) {
+	router := Classic()
+	router.Get("/api/user/:number", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/API/User/7", nil)
+	match, err := router.Match(req)
+	if err != nil {
+		t.Fatalf("Unexpected error (%s)", err.Error())
+	}
+
+	if match.Params["seg2"] != "7" {
+		t.Errorf("Unexpected parameter (Expected: 7, Actual: %s)", match.Params["seg2"])
+	}
+
+	if req.URL.Path != "/API/User/7" {
+		t.Errorf("Unexpected modified path (%s)", req.URL.Path)
+	}
+}
+
+func TestRouterMethodNotAllowedHandler(t *testing.T) {
+	router := Classic()
+	router.Get("/api/user", func(w http.ResponseWriter, r *http.Request) {})
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/api/user", nil))
+
+	if res.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Unexpected status code (Expected: %d, Actual: %d)", http.StatusMethodNotAllowed, res.Code)
+	}
+}
+
+func TestRouterExplain(t *testing.T) {
+	router := Classic()
+	router.Get("/api/user/:number", func(w http.ResponseWriter, r *http.Request) {})
+
+	trace := router.Explain(http.MethodGet, "/api/user/donutloop")
+	if trace.Err != ErrNotFound {
+		t.Fatalf("Unexpected error (Expected: %v, Actual: %v)", ErrNotFound, trace.Err)
+	}
+
+	last := trace.Steps[len(trace.Steps)-1]
+	if last.Node != ":number" || last.Segment != "donutloop" || last.Matched {
+		t.Errorf("Unexpected last step (%+v)", last)
+	}
+
+	trace = router.Explain(http.MethodPost, "/api/user/1")
+	if trace.Err != ErrMethodNotAllowed {
+		t.Errorf("Unexpected error (Expected: %v, Actual: %v)", ErrMethodNotAllowed, trace.Err)
+	}
+
+	if len(trace.Steps) != 3 || trace.Route == nil {
+		t.Errorf("Unexpected trace (%s)", trace)
+	}
+}
+
+func TestRouterAllowedMethods(t *testing.T) {
+	router := Classic()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	router.Get("/users/{id}", handler)
+	router.Delete("/users/{id}", handler)
+	router.Path("/users/{id}", func(route RouteInterface) {
+		route.(*Route).MatchHeader("X-Admin", "").AddHandlerFunc(http.MethodPatch, handler)
+	})
+	router.Path("/users/{id}", func(route RouteInterface) {
+		route.(HostRoute).SetHost("admin.example.com").AddHandlerFunc(http.MethodPut, handler)
+	})
+	router.Post("/users", handler)
+
+	testCases := []struct {
+		url     string
+		methods []string
+	}{
+		{url: "http://example.com/users/1", methods: []string{http.MethodDelete, http.MethodGet, http.MethodPatch}},
+		{url: "http://admin.example.com/users/1", methods: []string{http.MethodDelete, http.MethodGet, http.MethodPatch, http.MethodPut}},
+		{url: "http://example.com/USERS", methods: []string{http.MethodPost}},
+		{url: "http://example.com/orders", methods: nil},
+	}
+
+	for _, testCase := range testCases {
+		methods := router.AllowedMethods(httptest.NewRequest(http.MethodOptions, testCase.url, nil))
+
+		if strings.Join(methods, ",") != strings.Join(testCase.methods, ",") {
+			t.Errorf("%s: Unexpected methods (Expected: %v, Actual: %v)", testCase.url, testCase.methods, methods)
+		}
+	}
+}
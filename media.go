@@ -0,0 +1,129 @@
+package trixie
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// acceptedMedia is a media range of an Accept header
+type acceptedMedia struct {
+	mediaType string
+	params    map[string]string
+	q         float64
+}
+
+// parseAccept returns the media ranges of the Accept header, the most preferred first.
+// Ranges with quality 0 and invalid ranges are skipped.
+func parseAccept(req *http.Request) []acceptedMedia {
+	accepts := make([]acceptedMedia, 0)
+
+	for _, value := range req.Header["Accept"] {
+		for _, mediaRange := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+
+			q := 1.0
+			if v, found := params["q"]; found {
+				if q, err = strconv.ParseFloat(v, 64); err != nil || q <= 0 {
+					continue
+				}
+			}
+
+			accepts = append(accepts, acceptedMedia{mediaType: mediaType, params: params, q: q})
+		}
+	}
+
+	sort.SliceStable(accepts, func(i, j int) bool { return accepts[i].q > accepts[j].q })
+
+	return accepts
+}
+
+// mediaTypeMatches reports whether the media type matches the media range,
+// which may contain wildcards like */* or text/*. A type with a structured syntax
+// suffix (RFC 6839) like application/vnd.acme.v2+json matches its base type application/json.
+func mediaTypeMatches(mediaRange, mediaType string) bool {
+	mediaRange, mediaType = strings.ToLower(mediaRange), strings.ToLower(mediaType)
+
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+
+	if baseMediaType(mediaRange) == mediaType || baseMediaType(mediaType) == mediaRange {
+		return true
+	}
+
+	if strings.HasSuffix(mediaRange, "/*") {
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*"))
+	}
+
+	return false
+}
+
+// baseMediaType returns the type of the structured syntax suffix, e.g. application/json
+// for application/vnd.acme+json, a media type without suffix is returned unchanged.
+func baseMediaType(mediaType string) string {
+	slash, plus := strings.Index(mediaType, "/"), strings.LastIndex(mediaType, "+")
+	if slash < 0 || plus < slash {
+		return mediaType
+	}
+	return mediaType[:slash+1] + mediaType[plus+1:]
+}
+
+// producing reports whether one of the routes declares the media types it produces
+func producing(routes []RouteInterface) bool {
+	for _, route := range routes {
+		if len(producesOf(route)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// consumes reports whether the route accepts the body of the request.
+// A request without body and Content-Type is always accepted.
+func consumes(route RouteInterface, req *http.Request) bool {
+	if len(consumesOf(route)) == 0 {
+		return true
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	if contentType == "" {
+		return req.ContentLength == 0 && len(req.TransferEncoding) == 0
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, consumed := range consumesOf(route) {
+		if mediaTypeMatches(consumed, mediaType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// produces reports whether the route produces a media type the request accepts.
+// A request without Accept header accepts everything.
+func produces(route RouteInterface, req *http.Request) bool {
+	if len(producesOf(route)) == 0 || req.Header.Get("Accept") == "" {
+		return true
+	}
+
+	for _, accepted := range parseAccept(req) {
+		for _, produced := range producesOf(route) {
+			if mediaTypeMatches(accepted.mediaType, produced) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,80 @@
+package trixie
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouteMediaTypes(t *testing.T) {
+	router := Classic()
+
+	router.Post("/items", namedHandler("json")).(*Route).Consumes("application/json")
+	router.Post("/items", namedHandler("xml")).(*Route).Consumes("application/xml", "text/*")
+	router.Get("/items", namedHandler("get")).(*Route).Produces("application/json")
+	router.Delete("/items", namedHandler("delete")).(*Route).Consumes("application/json")
+
+	testCases := []struct {
+		method      string
+		contentType string
+		accept      string
+		body        string
+		statusCode  int
+		response    string
+	}{
+		{method: http.MethodPost, contentType: "application/json; charset=utf-8", body: "{}", statusCode: http.StatusOK, response: "json"},
+		{method: http.MethodPost, contentType: "text/xml", body: "<a/>", statusCode: http.StatusOK, response: "xml"},
+		{method: http.MethodPost, contentType: "application/merge-patch+json", body: "{}", statusCode: http.StatusOK, response: "json"},
+		{method: http.MethodPost, contentType: "image/png", body: "png", statusCode: http.StatusUnsupportedMediaType},
+		{method: http.MethodPost, body: "?", statusCode: http.StatusUnsupportedMediaType},
+		{method: http.MethodDelete, statusCode: http.StatusOK, response: "delete"},
+		{method: http.MethodGet, statusCode: http.StatusOK, response: "get"},
+		{method: http.MethodGet, accept: "application/*;q=0.8", statusCode: http.StatusOK, response: "get"},
+		{method: http.MethodGet, accept: "application/problem+json", statusCode: http.StatusOK, response: "get"},
+		{method: http.MethodGet, accept: "text/html, application/json;q=0", statusCode: http.StatusNotAcceptable},
+		{method: http.MethodGet, accept: "application/vnd.acme+xml", statusCode: http.StatusNotAcceptable},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(testCase.method, "/items", strings.NewReader(testCase.body))
+		if testCase.contentType != "" {
+			req.Header.Set("Content-Type", testCase.contentType)
+		}
+		if testCase.accept != "" {
+			req.Header.Set("Accept", testCase.accept)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != testCase.statusCode {
+			t.Errorf("%s (%s, %s): Unexpected status code (Expected: %d, Actual: %d)", testCase.method, testCase.contentType, testCase.accept, testCase.statusCode, rec.Code)
+			continue
+		}
+
+		if testCase.response != "" && rec.Body.String() != testCase.response {
+			t.Errorf("%s (%s, %s): Unexpected body (Expected: %s, Actual: %s)", testCase.method, testCase.contentType, testCase.accept, testCase.response, rec.Body.String())
+		}
+	}
+}
+
+func TestRouteMediaTypesVary(t *testing.T) {
+	router := Classic()
+	router.Get("/items", func(w http.ResponseWriter, r *http.Request) {}).(*Route).Produces("application/json")
+	router.Get("/users", func(w http.ResponseWriter, r *http.Request) {})
+
+	testCases := map[string]string{
+		"/items": "Accept",
+		"/users": "",
+	}
+
+	for path, vary := range testCases {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if rec.Header().Get("Vary") != vary {
+			t.Errorf("%s: Unexpected Vary header (Expected: %q, Actual: %q)", path, vary, rec.Header().Get("Vary"))
+		}
+	}
+}
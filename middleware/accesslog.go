@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// AccessLogFormat is the format of the access log
+type AccessLogFormat int
+
+const (
+	// SlogFormat emits one structured record per request via log/slog
+	SlogFormat AccessLogFormat = iota
+	// CommonLogFormat writes lines in the Common Log Format
+	CommonLogFormat
+	// CombinedLogFormat writes lines in the Combined Log Format (Common plus referer and user agent)
+	CombinedLogFormat
+)
+
+// AccessLogEntry describes a served request.
+type AccessLogEntry struct {
+	Time       time.Time
+	Method     string
+	Path       string
+	Proto      string
+	RemoteAddr string
+	// Route is the pattern of the route matched by the router, it's empty
+	// if no route matched.
+	Route     string
+	Params    map[string]string
+	Status    int
+	Bytes     int64
+	Latency   time.Duration
+	RequestID string
+	Referer   string
+	UserAgent string
+}
+
+// AccessLogOptions configures the AccessLog middleware.
+type AccessLogOptions struct {
+	// Format of the log, slog by default
+	Format AccessLogFormat
+	// Logger for the slog format, slog.Default() by default
+	Logger *slog.Logger
+	// Writer for the log formats, os.Stdout by default
+	Writer io.Writer
+	// PatternAsPath logs the route pattern instead of the raw path in the log formats,
+	// which keeps the cardinality of the log low. The path is logged if no route matched.
+	PatternAsPath bool
+	// Params returns the route parameters of a request, e.g. trixie.GetRouteParameters
+	Params func(*http.Request) map[string]string
+	// RequestID returns the ID of a request, by default the ID of the RequestID
+	// middleware or else the X-Request-ID header
+	RequestID func(*http.Request) string
+}
+
+// AccessLog is a middleware which logs every request after it's served.
+//
+// Used with router.Use the entry holds the matched route and its params,
+// used with router.UsePreRouting it also logs requests which didn't match.
+func AccessLog(options AccessLogOptions) Middleware {
+	if options.Logger == nil {
+		options.Logger = slog.Default()
+	}
+
+	if options.Writer == nil {
+		options.Writer = os.Stdout
+	}
+
+	if options.RequestID == nil {
+		options.RequestID = func(r *http.Request) string {
+			if id := GetRequestID(r); id != "" {
+				return id
+			}
+			return r.Header.Get(DefaultRequestIDHeader)
+		}
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := WrapResponseWriter(w)
+
+			h.ServeHTTP(rw, r)
+
+			entry := AccessLogEntry{
+				Time:       start,
+				Method:     r.Method,
+				Path:       r.URL.RequestURI(),
+				Proto:      r.Proto,
+				RemoteAddr: r.RemoteAddr,
+				Route:      routePattern(r),
+				Status:     rw.Status(),
+				Bytes:      rw.BytesWritten(),
+				Latency:    time.Since(start),
+				RequestID:  options.RequestID(r),
+				Referer:    r.Referer(),
+				UserAgent:  r.UserAgent(),
+			}
+
+			if entry.Status == 0 {
+				entry.Status = http.StatusOK
+			}
+
+			if options.Params != nil {
+				entry.Params = options.Params(r)
+			}
+
+			switch options.Format {
+			case CommonLogFormat, CombinedLogFormat:
+				fmt.Fprintln(options.Writer, formatAccessLog(entry, options))
+			default:
+				logAccess(options.Logger, entry)
+			}
+		})
+	}
+}
+
+func logAccess(logger *slog.Logger, entry AccessLogEntry) {
+	attrs := []slog.Attr{
+		slog.String("method", entry.Method),
+		slog.String("path", entry.Path),
+		slog.String("route", entry.Route),
+		slog.Int("status", entry.Status),
+		slog.Int64("bytes", entry.Bytes),
+		slog.Duration("latency", entry.Latency),
+	}
+
+	if len(entry.Params) > 0 {
+		params := make([]any, 0, len(entry.Params))
+		for name, value := range entry.Params {
+			params = append(params, slog.String(name, value))
+		}
+		attrs = append(attrs, slog.Group("params", params...))
+	}
+
+	if entry.RequestID != "" {
+		attrs = append(attrs, slog.String("request_id", entry.RequestID))
+	}
+
+	logger.LogAttrs(context.Background(), slog.LevelInfo, "request", attrs...)
+}
+
+// formatAccessLog formats the entry in the Common or Combined Log Format:
+//
+//	127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /users/1 HTTP/1.1" 200 2326 "http://example.com/" "Mozilla/5.0"
+func formatAccessLog(entry AccessLogEntry, options AccessLogOptions) string {
+	host := entry.RemoteAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	path := entry.Path
+	if options.PatternAsPath && entry.Route != "" {
+		path = entry.Route
+	}
+
+	size := "-"
+	if entry.Bytes > 0 {
+		size = fmt.Sprint(entry.Bytes)
+	}
+
+	line := fmt.Sprintf("%s - - [%s] %q %d %s",
+		dash(host), entry.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		strings.Join([]string{entry.Method, path, entry.Proto}, " "), entry.Status, size)
+
+	if options.Format == CombinedLogFormat {
+		line += fmt.Sprintf(" %q %q", entry.Referer, entry.UserAgent)
+	}
+
+	return line
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,128 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/donutloop/trixie"
+	"github.com/donutloop/trixie/middleware"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestAccessLogSlog(t *testing.T) {
+	var buf bytes.Buffer
+
+	router := trixie.Classic()
+	router.Use(middleware.AccessLog(middleware.AccessLogOptions{
+		Logger: slog.New(slog.NewJSONHandler(&buf, nil)),
+		Params: trixie.GetRouteParameters,
+	}))
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+	req.Header.Set("X-Request-ID", "abc")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var record struct {
+		Method    string            `json:"method"`
+		Path      string            `json:"path"`
+		Route     string            `json:"route"`
+		Status    int               `json:"status"`
+		Bytes     int64             `json:"bytes"`
+		RequestID string            `json:"request_id"`
+		Params    map[string]string `json:"params"`
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Unexpected log record %s (%s)", buf.String(), err.Error())
+	}
+
+	if record.Method != http.MethodGet || record.Path != "/users/7" || record.Route != "/users/{id}" ||
+		record.Status != http.StatusCreated || record.Bytes != 5 || record.RequestID != "abc" || record.Params["id"] != "7" {
+		t.Errorf("Unexpected log record (%s)", buf.String())
+	}
+}
+
+func TestAccessLogCombinedFormat(t *testing.T) {
+	var buf bytes.Buffer
+
+	router := trixie.Classic()
+	router.UsePreRouting(middleware.AccessLog(middleware.AccessLogOptions{
+		Format: middleware.CombinedLogFormat,
+		Writer: &buf,
+	}))
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/missing?x=1", nil)
+	req.Header.Set("User-Agent", "test")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := regexp.MustCompile(`^192\.0\.2\.1 - - \[[^\]]+\] "GET /missing\?x=1 HTTP/1\.1" 404 19 "" "test"\n$`)
+	if !line.MatchString(buf.String()) {
+		t.Errorf("Unexpected log line (%s)", buf.String())
+	}
+}
+
+func TestAccessLogPatternAsPath(t *testing.T) {
+	var buf bytes.Buffer
+
+	router := trixie.Classic()
+	router.Use(middleware.AccessLog(middleware.AccessLogOptions{
+		Format:        middleware.CommonLogFormat,
+		Writer:        &buf,
+		PatternAsPath: true,
+	}))
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/7", nil))
+
+	line := regexp.MustCompile(`^192\.0\.2\.1 - - \[[^\]]+\] "GET /users/\{id\} HTTP/1\.1" 200 -\n$`)
+	if !line.MatchString(buf.String()) {
+		t.Errorf("Unexpected log line (%s)", buf.String())
+	}
+}
+
+func TestAccessLogPreRouting(t *testing.T) {
+	var buf bytes.Buffer
+
+	router := trixie.Classic()
+	router.UsePreRouting(middleware.AccessLog(middleware.AccessLogOptions{
+		Logger: slog.New(slog.NewJSONHandler(&buf, nil)),
+		Params: trixie.GetRouteParameters,
+	}))
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	testCases := []struct {
+		path  string
+		route string
+		id    string
+	}{
+		{path: "/users/7", route: "/users/{id}", id: "7"},
+		{path: "/missing", route: "", id: ""},
+	}
+
+	for _, testCase := range testCases {
+		buf.Reset()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, testCase.path, nil))
+
+		var record struct {
+			Route  string            `json:"route"`
+			Params map[string]string `json:"params"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+
+		if record.Route != testCase.route || record.Params["id"] != testCase.id {
+			t.Errorf("%s: Unexpected route or params (Expected: %s %s, Actual: %s %v)", testCase.path, testCase.route, testCase.id, record.Route, record.Params)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package middleware
 
-import "net/http"
+import (
+	"net/http"
+	"path"
+)
 
 type ContextKey string
 
@@ -12,6 +15,8 @@ type Middleware func(http.Handler) http.Handler
 // the same set of constructors in the same order.
 type Chain struct {
 	middleware []Middleware
+	// names of the middleware, unnamed middleware has an empty name
+	names []string
 }
 
 // New creates a new chain,
@@ -19,7 +24,10 @@ type Chain struct {
 // New serves no other function,
 // constructors are only called upon a call to Then().
 func Stack(middleware ...Middleware) Chain {
-	return Chain{middleware: append(make([]Middleware, 0, len(middleware)), middleware...)}
+	return Chain{
+		middleware: append(make([]Middleware, 0, len(middleware)), middleware...),
+		names:      make([]string, len(middleware)),
+	}
 }
 
 // Then chains the middleware and returns the final http.Handler.
@@ -69,3 +77,119 @@ func (c Chain) ThenFunc(endpointFunc http.HandlerFunc) http.Handler {
 
 	return c.Then(endpointFunc)
 }
+
+// Append returns a new chain with the middleware added after the middleware of c.
+func (c Chain) Append(middleware ...Middleware) Chain {
+	return c.Extend(Stack(middleware...))
+}
+
+// AppendNamed returns a new chain with the named middleware added after the middleware of c.
+// The name can be used to inspect or remove the middleware.
+func (c Chain) AppendNamed(name string, m Middleware) Chain {
+	chain := c.Append(m)
+	chain.names[len(chain.names)-1] = name
+	return chain
+}
+
+// Extend returns a new chain with the middleware of chain added after the middleware of c.
+func (c Chain) Extend(chain Chain) Chain {
+	extended := Chain{
+		middleware: make([]Middleware, 0, len(c.middleware)+len(chain.middleware)),
+		names:      make([]string, 0, len(c.middleware)+len(chain.middleware)),
+	}
+
+	extended.middleware = append(append(extended.middleware, c.middleware...), chain.middleware...)
+	extended.names = append(append(extended.names, c.paddedNames()...), chain.paddedNames()...)
+
+	return extended
+}
+
+// Prepend returns a new chain with the middleware added before the middleware of c.
+func (c Chain) Prepend(middleware ...Middleware) Chain {
+	return Stack(middleware...).Extend(c)
+}
+
+// Len returns the count of middleware in the chain.
+func (c Chain) Len() int {
+	return len(c.middleware)
+}
+
+// Names returns the names of the middleware in order, unnamed middleware has an empty name.
+func (c Chain) Names() []string {
+	return c.paddedNames()
+}
+
+// Get returns the middleware with the given name.
+func (c Chain) Get(name string) (Middleware, bool) {
+	for i, n := range c.paddedNames() {
+		if n == name && name != "" {
+			return c.middleware[i], true
+		}
+	}
+	return nil, false
+}
+
+// Remove returns a new chain without the middleware with the given name.
+func (c Chain) Remove(name string) Chain {
+	removed := Chain{}
+	for i, n := range c.paddedNames() {
+		if n == name && name != "" {
+			continue
+		}
+		removed.middleware = append(removed.middleware, c.middleware[i])
+		removed.names = append(removed.names, n)
+	}
+	return removed
+}
+
+// paddedNames returns a copy of the names, which also works for the zero chain
+func (c Chain) paddedNames() []string {
+	names := make([]string, len(c.middleware))
+	copy(names, c.names)
+	return names
+}
+
+// When returns a middleware which only runs m if the predicate is true for the request,
+// otherwise the request is passed to the next handler directly.
+func When(predicate func(*http.Request) bool, m Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		wrapped := m(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if predicate(r) {
+				wrapped.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Unless returns a middleware which only runs m if the predicate is false for the request.
+func Unless(predicate func(*http.Request) bool, m Middleware) Middleware {
+	return When(func(r *http.Request) bool { return !predicate(r) }, m)
+}
+
+// SkipPaths returns a middleware which doesn't run m for requests whose path matches
+// one of the patterns. Patterns use the syntax of path.Match, e.g. /health or /static/*.
+func SkipPaths(m Middleware, patterns ...string) Middleware {
+	return Unless(func(r *http.Request) bool {
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, r.URL.Path); matched {
+				return true
+			}
+		}
+		return false
+	}, m)
+}
+
+// SkipMethods returns a middleware which doesn't run m for requests with one of the methods.
+func SkipMethods(m Middleware, methods ...string) Middleware {
+	return Unless(func(r *http.Request) bool {
+		for _, method := range methods {
+			if r.Method == method {
+				return true
+			}
+		}
+		return false
+	}, m)
+}
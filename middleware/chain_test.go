@@ -0,0 +1,88 @@
+package middleware_test
+
+import (
+	"github.com/donutloop/trixie/middleware"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// tag returns a middleware which appends its name to the X-Trace header
+func tag(name string) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Trace", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func trace(chain middleware.Chain, method, path string) string {
+	rec := httptest.NewRecorder()
+	chain.ThenFunc(func(w http.ResponseWriter, r *http.Request) {}).ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return strings.Join(rec.Header()["X-Trace"], ",")
+}
+
+func TestChainComposition(t *testing.T) {
+	base := middleware.Stack(tag("b"))
+
+	testCases := []struct {
+		name     string
+		chain    middleware.Chain
+		expected string
+	}{
+		{name: "append", chain: base.Append(tag("c"), tag("d")), expected: "b,c,d"},
+		{name: "prepend", chain: base.Prepend(tag("a")), expected: "a,b"},
+		{name: "extend", chain: base.Extend(middleware.Stack(tag("x"), tag("y"))), expected: "b,x,y"},
+		{name: "remove", chain: base.AppendNamed("auth", tag("auth")).Append(tag("c")).Remove("auth"), expected: "b,c"},
+		{name: "base", chain: base, expected: "b"},
+	}
+
+	for _, testCase := range testCases {
+		if actual := trace(testCase.chain, http.MethodGet, "/"); actual != testCase.expected {
+			t.Errorf("%s: Unexpected middleware (Expected: %s, Actual: %s)", testCase.name, testCase.expected, actual)
+		}
+	}
+
+	chain := middleware.Chain{}.Append(tag("a")).AppendNamed("auth", tag("auth"))
+	if names := chain.Names(); chain.Len() != 2 || names[0] != "" || names[1] != "auth" {
+		t.Errorf("Unexpected names (%v)", names)
+	}
+
+	if _, found := chain.Get("auth"); !found {
+		t.Error("Unexpected missing named middleware")
+	}
+
+	if _, found := chain.Remove("auth").Get("auth"); found {
+		t.Error("Unexpected removed middleware")
+	}
+}
+
+func TestConditionalMiddleware(t *testing.T) {
+	isAPI := func(r *http.Request) bool { return strings.HasPrefix(r.URL.Path, "/api/") }
+
+	chain := middleware.Stack(
+		middleware.When(isAPI, tag("when")),
+		middleware.Unless(isAPI, tag("unless")),
+		middleware.SkipPaths(tag("paths"), "/health", "/static/*"),
+		middleware.SkipMethods(tag("methods"), http.MethodOptions),
+	)
+
+	testCases := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{method: http.MethodGet, path: "/api/users", expected: "when,paths,methods"},
+		{method: http.MethodGet, path: "/home", expected: "unless,paths,methods"},
+		{method: http.MethodGet, path: "/static/app.js", expected: "unless,methods"},
+		{method: http.MethodOptions, path: "/health", expected: "unless"},
+	}
+
+	for _, testCase := range testCases {
+		if actual := trace(chain, testCase.method, testCase.path); actual != testCase.expected {
+			t.Errorf("%s %s: Unexpected middleware (Expected: %s, Actual: %s)", testCase.method, testCase.path, testCase.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures the CORS middleware.
+type CORSOptions struct {
+	// AllowedOrigins are the origins which may access the resources. An origin is
+	// either exact (https://example.com), a wildcard subdomain (https://*.example.com)
+	// or * for all origins.
+	AllowedOrigins []string
+	// AllowOriginFunc reports whether an origin is allowed, it's asked if none of the AllowedOrigins matches
+	AllowOriginFunc func(origin string, r *http.Request) bool
+	// AllowedMethods returns the methods of the requested resource, e.g. router.AllowedMethods.
+	// GET, HEAD and POST are allowed if it's nil.
+	AllowedMethods func(r *http.Request) []string
+	// AllowedHeaders are the headers the actual request may carry, * allows all headers.
+	// The requested headers are allowed if it's empty.
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers the client may read
+	ExposedHeaders []string
+	// AllowCredentials allows requests with cookies or authorization headers,
+	// it can't be combined with * in AllowedOrigins.
+	AllowCredentials bool
+	// MaxAge is the time the client may cache the answer to a preflight request
+	MaxAge time.Duration
+}
+
+// CORS is a middleware which implements Cross-Origin Resource Sharing.
+//
+// It answers preflight requests (OPTIONS with Access-Control-Request-Method) itself
+// with the methods of the requested resource, so no OPTIONS routes have to be registered.
+// A preflight for a path without routes is passed on, so it gets the not found handler.
+// Register it with router.UsePreRouting, middleware registered with router.Use
+// never sees a preflight request because no OPTIONS route matches.
+// Responses get Vary: Origin unless all origins are allowed by *.
+//
+// CORS panics if AllowedOrigins contains * and AllowCredentials is set, as any site could
+// read the responses to requests with the credentials of the user.
+//
+//	r.UsePreRouting(middleware.CORS(middleware.CORSOptions{
+//		AllowedOrigins: []string{"https://*.example.com"},
+//		AllowedMethods: r.AllowedMethods,
+//	}))
+func CORS(options CORSOptions) Middleware {
+	if options.AllowCredentials && slices.Contains(options.AllowedOrigins, "*") {
+		panic("cors: all origins (*) can't be allowed with credentials")
+	}
+
+	if options.AllowedMethods == nil {
+		options.AllowedMethods = func(r *http.Request) []string {
+			return []string{http.MethodGet, http.MethodHead, http.MethodPost}
+		}
+	}
+
+	// the answer depends on the origin unless it's always *
+	varyOrigin := !slices.Contains(options.AllowedOrigins, "*")
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				preflight(w, r, h, origin, varyOrigin, options)
+				return
+			}
+
+			if varyOrigin {
+				w.Header().Add("Vary", "Origin")
+			}
+
+			if origin != "" && allowOrigin(origin, r, options) {
+				setAllowOrigin(w, origin, options)
+
+				if len(options.ExposedHeaders) > 0 {
+					w.Header().Set("Access-Control-Expose-Headers", strings.Join(options.ExposedHeaders, ", "))
+				}
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// preflight answers a preflight request with 204 No Content. The CORS headers are
+// left out if the origin, the method or a header isn't allowed, so the client refuses
+// the actual request.
+func preflight(w http.ResponseWriter, r *http.Request, h http.Handler, origin string, varyOrigin bool, options CORSOptions) {
+	methods := options.AllowedMethods(r)
+	if len(methods) == 0 {
+		h.ServeHTTP(w, r)
+		return
+	}
+
+	header := w.Header()
+	if varyOrigin {
+		header.Add("Vary", "Origin")
+	}
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+
+	method := r.Header.Get("Access-Control-Request-Method")
+	headers := requestedHeaders(r)
+
+	if origin == "" || !allowOrigin(origin, r, options) || !slices.Contains(methods, method) || !allowHeaders(headers, options) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	setAllowOrigin(w, origin, options)
+	header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+	if len(headers) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	}
+
+	if options.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(options.MaxAge/time.Second)))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setAllowOrigin sets the allowed origin, * if all origins are allowed
+func setAllowOrigin(w http.ResponseWriter, origin string, options CORSOptions) {
+	if slices.Contains(options.AllowedOrigins, "*") {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if options.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// allowOrigin reports whether one of the allowed origins or the origin func accepts the origin
+func allowOrigin(origin string, r *http.Request, options CORSOptions) bool {
+	for _, allowed := range options.AllowedOrigins {
+		if matchOrigin(allowed, origin) {
+			return true
+		}
+	}
+
+	return options.AllowOriginFunc != nil && options.AllowOriginFunc(origin, r)
+}
+
+// matchOrigin matches an origin against an exact origin, a wildcard subdomain or *
+func matchOrigin(allowed, origin string) bool {
+	if allowed == "*" {
+		return true
+	}
+
+	prefix, suffix, wildcard := strings.Cut(strings.ToLower(allowed), "*")
+	origin = strings.ToLower(origin)
+	if !wildcard {
+		return prefix == origin
+	}
+
+	return len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
+
+// requestedHeaders returns the lower case headers of Access-Control-Request-Headers
+func requestedHeaders(r *http.Request) []string {
+	var headers []string
+	for _, value := range r.Header.Values("Access-Control-Request-Headers") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				headers = append(headers, name)
+			}
+		}
+	}
+	return headers
+}
+
+// allowHeaders reports whether all requested headers are allowed
+func allowHeaders(headers []string, options CORSOptions) bool {
+	if len(options.AllowedHeaders) == 0 || slices.Contains(options.AllowedHeaders, "*") {
+		return true
+	}
+
+	for _, name := range headers {
+		if !slices.ContainsFunc(options.AllowedHeaders, func(allowed string) bool { return strings.EqualFold(allowed, name) }) {
+			return false
+		}
+	}
+
+	return true
+}
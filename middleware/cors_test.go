@@ -0,0 +1,182 @@
+package middleware_test
+
+import (
+	"github.com/donutloop/trixie"
+	"github.com/donutloop/trixie/middleware"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCORSPreflight(t *testing.T) {
+	router := trixie.Classic()
+	router.UsePreRouting(middleware.CORS(middleware.CORSOptions{
+		AllowedOrigins:   []string{"https://example.com", "https://*.example.org"},
+		AllowOriginFunc:  func(origin string, r *http.Request) bool { return origin == "http://localhost:3000" },
+		AllowedMethods:   router.AllowedMethods,
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}))
+
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	router.Get("/users/{id}", handler)
+	router.Delete("/users/{id}", handler)
+
+	testCases := []struct {
+		path    string
+		origin  string
+		method  string
+		headers string
+		status  int
+		allowed bool
+	}{
+		{path: "/users/1", origin: "https://example.com", method: http.MethodDelete, headers: "Authorization", status: http.StatusNoContent, allowed: true},
+		{path: "/users/1", origin: "https://api.example.org", method: http.MethodGet, status: http.StatusNoContent, allowed: true},
+		{path: "/users/1", origin: "http://localhost:3000", method: http.MethodGet, headers: "content-type, authorization", status: http.StatusNoContent, allowed: true},
+		{path: "/users/1", origin: "https://example.org", method: http.MethodGet, status: http.StatusNoContent},
+		{path: "/users/1", origin: "https://evil.com", method: http.MethodGet, status: http.StatusNoContent},
+		{path: "/users/1", origin: "https://example.com", method: http.MethodPut, status: http.StatusNoContent},
+		{path: "/users/1", origin: "https://example.com", method: http.MethodGet, headers: "X-Custom", status: http.StatusNoContent},
+		{path: "/orders/1", origin: "https://example.com", method: http.MethodGet, status: http.StatusNotFound},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(http.MethodOptions, testCase.path, nil)
+		req.Header.Set("Origin", testCase.origin)
+		req.Header.Set("Access-Control-Request-Method", testCase.method)
+		if testCase.headers != "" {
+			req.Header.Set("Access-Control-Request-Headers", testCase.headers)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != testCase.status {
+			t.Errorf("%s %s %s: Unexpected status (Expected: %d, Actual: %d)", testCase.origin, testCase.method, testCase.path, testCase.status, rec.Code)
+		}
+
+		origin := rec.Header().Get("Access-Control-Allow-Origin")
+		if allowed := origin != ""; allowed != testCase.allowed {
+			t.Errorf("%s %s %s: Unexpected allowed origin (%q)", testCase.origin, testCase.method, testCase.path, origin)
+			continue
+		}
+
+		if !testCase.allowed {
+			continue
+		}
+
+		expected := map[string]string{
+			"Access-Control-Allow-Origin":      testCase.origin,
+			"Access-Control-Allow-Methods":     "DELETE, GET",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Max-Age":           "600",
+			"Access-Control-Allow-Headers":     strings.ToLower(strings.ReplaceAll(testCase.headers, " ", "")),
+		}
+
+		for name, value := range expected {
+			actual := rec.Header().Get(name)
+			if name == "Access-Control-Allow-Headers" {
+				actual = strings.ReplaceAll(actual, " ", "")
+			}
+			if actual != value {
+				t.Errorf("%s %s %s: Unexpected header %s (Expected: %q, Actual: %q)", testCase.origin, testCase.method, testCase.path, name, value, actual)
+			}
+		}
+	}
+}
+
+func TestCORSActualRequest(t *testing.T) {
+	var served bool
+	router := trixie.Classic()
+	router.UsePreRouting(middleware.CORS(middleware.CORSOptions{
+		AllowedOrigins: []string{"*"},
+		ExposedHeaders: []string{"X-Request-ID"},
+	}))
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		served = true
+	})
+
+	testCases := []struct {
+		origin      string
+		allowOrigin string
+		exposed     string
+	}{
+		{origin: "https://example.com", allowOrigin: "*", exposed: "X-Request-ID"},
+		{origin: "", allowOrigin: "", exposed: ""},
+	}
+
+	for _, testCase := range testCases {
+		served = false
+
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		if testCase.origin != "" {
+			req.Header.Set("Origin", testCase.origin)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if !served {
+			t.Errorf("%q: Unexpected request which wasn't served", testCase.origin)
+		}
+		if origin := rec.Header().Get("Access-Control-Allow-Origin"); origin != testCase.allowOrigin {
+			t.Errorf("%q: Unexpected allowed origin (Expected: %q, Actual: %q)", testCase.origin, testCase.allowOrigin, origin)
+		}
+		if exposed := rec.Header().Get("Access-Control-Expose-Headers"); exposed != testCase.exposed {
+			t.Errorf("%q: Unexpected exposed headers (Expected: %q, Actual: %q)", testCase.origin, testCase.exposed, exposed)
+		}
+		if vary := rec.Header().Get("Vary"); vary != "" {
+			t.Errorf("%q: Unexpected Vary header for * (%q)", testCase.origin, vary)
+		}
+	}
+
+	// without AllowedMethods the simple methods are allowed
+	req := httptest.NewRequest(http.MethodOptions, "/users/1", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	req.Header.Set("Access-Control-Request-Headers", "X-Anything")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if methods := rec.Header().Get("Access-Control-Allow-Methods"); methods != "GET, HEAD, POST" {
+		t.Errorf("Unexpected allowed methods (%q)", methods)
+	}
+	if headers := rec.Header().Get("Access-Control-Allow-Headers"); headers != "x-anything" {
+		t.Errorf("Unexpected allowed headers (%q)", headers)
+	}
+}
+
+func TestCORSVaryOrigin(t *testing.T) {
+	router := trixie.Classic()
+	router.UsePreRouting(middleware.CORS(middleware.CORSOptions{AllowedOrigins: []string{"https://example.com"}}))
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	// a cached answer without Origin mustn't be reused for a request with Origin
+	for _, origin := range []string{"https://example.com", "https://evil.com", ""} {
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if vary := rec.Header().Get("Vary"); vary != "Origin" {
+			t.Errorf("%q: Unexpected Vary header (Expected: %q, Actual: %q)", origin, "Origin", vary)
+		}
+	}
+}
+
+func TestCORSWildcardWithCredentials(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for * with credentials")
+		}
+	}()
+
+	middleware.CORS(middleware.CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import "net/http"
+
+// MatchKey is the context key for the match of the router
+const MatchKey ContextKey = "match"
+
+// Match holds the route the router matched for a request.
+//
+// The router adds an empty match to the request before the pre-routing middleware
+// runs and fills it in when a route matched. Pre-routing middleware only sees the
+// request before the lookup, so it reads the match after calling the next handler.
+type Match struct {
+	// Pattern of the matched route, it's empty if no route matched
+	Pattern string
+	// Params of the path and the host
+	Params map[string]string
+}
+
+// GetMatch returns the match of the request, it's nil if the request isn't served by a router
+// with pre-routing middleware.
+func GetMatch(r *http.Request) *Match {
+	if rv := r.Context().Value(MatchKey); rv != nil {
+		return rv.(*Match)
+	}
+	return nil
+}
+
+// routePattern returns the pattern of the route matched by the router (req.Pattern),
+// for pre-routing middleware it's taken from the match once the router is done.
+func routePattern(r *http.Request) string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+
+	if match := GetMatch(r); match != nil {
+		return match.Pattern
+	}
+
+	return ""
+}
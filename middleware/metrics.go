@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds of the latency histogram in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// MetricsOptions configures the metrics.
+type MetricsOptions struct {
+	// Namespace is the prefix of the metric names, e.g. myapp for myapp_http_requests_total
+	Namespace string
+	// Buckets of the latency histogram in seconds, DefaultBuckets by default
+	Buckets []float64
+}
+
+// Metrics collects request counters, in-flight gauges and latency histograms labelled by
+// method, route pattern and status class (2xx, 4xx, ...) and exposes them in the
+// Prometheus text format.
+//
+// The route pattern is taken from the matched route (req.Pattern or the Match for pre-routing
+// middleware). Requests without route are labelled with an empty route, the in-flight gauge
+// of pre-routing middleware too as the route isn't known before the lookup.
+// Methods which aren't defined by RFC 9110 or RFC 5789 are labelled as other,
+// so arbitrary methods of clients can't create new series.
+type Metrics struct {
+	namespace string
+	buckets   []float64
+
+	mu        sync.Mutex
+	requests  map[metricLabels]uint64
+	inFlight  map[metricLabels]int64
+	durations map[metricLabels]*histogram
+}
+
+// metricLabels are the labels of a series, the in-flight gauge has no status
+type metricLabels struct {
+	method string
+	route  string
+	status string
+}
+
+// metricMethods are the methods which are kept as label
+var metricMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+func metricMethod(method string) string {
+	if metricMethods[method] {
+		return method
+	}
+	return "other"
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewMetrics returns empty metrics.
+func NewMetrics(options MetricsOptions) *Metrics {
+	buckets := options.Buckets
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	namespace := options.Namespace
+	if namespace != "" {
+		namespace += "_"
+	}
+
+	return &Metrics{
+		namespace: namespace,
+		buckets:   buckets,
+		requests:  map[metricLabels]uint64{},
+		inFlight:  map[metricLabels]int64{},
+		durations: map[metricLabels]*histogram{},
+	}
+}
+
+// Middleware returns a middleware which records the requests.
+func (m *Metrics) Middleware() Middleware {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			labels := metricLabels{method: metricMethod(r.Method), route: r.Pattern}
+
+			m.mu.Lock()
+			m.inFlight[labels]++
+			m.mu.Unlock()
+
+			rw := WrapResponseWriter(w)
+			defer func() {
+				status := rw.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+				m.observe(labels, routePattern(r), status, time.Since(start))
+			}()
+
+			h.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// observe records a finished request, the route is known once the router is done
+// even for pre-routing middleware (see Match).
+func (m *Metrics) observe(labels metricLabels, route string, status int, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight[labels]--
+
+	labels.route = route
+
+	labels.status = strconv.Itoa(status/100) + "xx"
+	m.requests[labels]++
+
+	hist, found := m.durations[labels]
+	if !found {
+		hist = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.durations[labels] = hist
+	}
+
+	seconds := latency.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			hist.counts[i]++
+		}
+	}
+	hist.sum += seconds
+	hist.count++
+}
+
+// Handler returns a handler which exposes the metrics in the Prometheus text format,
+// it can be registered on any route, e.g. router.HandleFunc(http.MethodGet, "/metrics", metrics.Handler())
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WriteTo(w)
+	})
+}
+
+// WriteTo writes the metrics in the Prometheus text format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	name := m.namespace + "http_requests_total"
+	fmt.Fprintf(&b, "# HELP %s Count of served requests.\n# TYPE %s counter\n", name, name)
+	for _, labels := range sortedLabels(m.requests) {
+		fmt.Fprintf(&b, "%s{%s} %d\n", name, labels.format(), m.requests[labels])
+	}
+
+	name = m.namespace + "http_requests_in_flight"
+	fmt.Fprintf(&b, "# HELP %s Count of requests being served.\n# TYPE %s gauge\n", name, name)
+	for _, labels := range sortedLabels(m.inFlight) {
+		fmt.Fprintf(&b, "%s{%s} %d\n", name, labels.format(), m.inFlight[labels])
+	}
+
+	name = m.namespace + "http_request_duration_seconds"
+	fmt.Fprintf(&b, "# HELP %s Latency of served requests.\n# TYPE %s histogram\n", name, name)
+	for _, labels := range sortedLabels(m.durations) {
+		hist := m.durations[labels]
+		for i, bound := range m.buckets {
+			fmt.Fprintf(&b, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels.format(), formatFloat(bound), hist.counts[i])
+		}
+		fmt.Fprintf(&b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels.format(), hist.count)
+		fmt.Fprintf(&b, "%s_sum{%s} %s\n", name, labels.format(), formatFloat(hist.sum))
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", name, labels.format(), hist.count)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// format returns the labels in the exposition format, the status is omitted if it's empty
+func (l metricLabels) format() string {
+	s := fmt.Sprintf("method=\"%s\",route=\"%s\"", escapeLabel(l.method), escapeLabel(l.route))
+	if l.status != "" {
+		s += fmt.Sprintf(",status=\"%s\"", l.status)
+	}
+	return s
+}
+
+func sortedLabels[V any](series map[metricLabels]V) []metricLabels {
+	labels := make([]metricLabels, 0, len(series))
+	for l := range series {
+		labels = append(labels, l)
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].route != labels[j].route {
+			return labels[i].route < labels[j].route
+		}
+		if labels[i].method != labels[j].method {
+			return labels[i].method < labels[j].method
+		}
+		return labels[i].status < labels[j].status
+	})
+
+	return labels
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
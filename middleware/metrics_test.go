@@ -0,0 +1,93 @@
+package middleware_test
+
+import (
+	"github.com/donutloop/trixie"
+	"github.com/donutloop/trixie/middleware"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := middleware.NewMetrics(middleware.MetricsOptions{Namespace: "app", Buckets: []float64{1, 0.5}})
+
+	router := trixie.Classic()
+	router.Use(metrics.Middleware())
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "0" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	router.HandleFunc(http.MethodGet, "/metrics", metrics.Handler())
+
+	for _, path := range []string{"/users/1", "/users/2", "/users/0"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if contentType := rec.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected content type (%s)", contentType)
+	}
+
+	expected := []string{
+		"# TYPE app_http_requests_total counter",
+		`app_http_requests_total{method="GET",route="/users/{id}",status="2xx"} 2`,
+		`app_http_requests_total{method="GET",route="/users/{id}",status="4xx"} 1`,
+		"# TYPE app_http_requests_in_flight gauge",
+		`app_http_requests_in_flight{method="GET",route="/metrics"} 1`,
+		`app_http_requests_in_flight{method="GET",route="/users/{id}"} 0`,
+		"# TYPE app_http_request_duration_seconds histogram",
+		`app_http_request_duration_seconds_bucket{method="GET",route="/users/{id}",status="2xx",le="0.5"} 2`,
+		`app_http_request_duration_seconds_bucket{method="GET",route="/users/{id}",status="2xx",le="1"} 2`,
+		`app_http_request_duration_seconds_bucket{method="GET",route="/users/{id}",status="2xx",le="+Inf"} 2`,
+		`app_http_request_duration_seconds_count{method="GET",route="/users/{id}",status="4xx"} 1`,
+	}
+
+	body := rec.Body.String()
+	for _, line := range expected {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Unexpected missing line %s in\n%s", line, body)
+		}
+	}
+
+	if strings.Contains(body, "/users/1") {
+		t.Errorf("Unexpected path label in\n%s", body)
+	}
+}
+
+func TestMetricsPreRouting(t *testing.T) {
+	metrics := middleware.NewMetrics(middleware.MetricsOptions{})
+
+	router := trixie.Classic()
+	router.UsePreRouting(metrics.Middleware())
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, path := range []string{"/users/1", "/users/2", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	for _, method := range []string{"FOO", "BAR"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/users/1", nil))
+	}
+
+	buf := &strings.Builder{}
+	metrics.WriteTo(buf)
+	body := buf.String()
+
+	for _, line := range []string{
+		`http_requests_total{method="GET",route="/users/{id}",status="2xx"} 2`,
+		`http_requests_total{method="GET",route="",status="4xx"} 1`,
+		`http_requests_total{method="other",route="",status="4xx"} 2`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Unexpected missing line %s in\n%s", line, body)
+		}
+	}
+
+	if strings.Contains(body, "FOO") {
+		t.Errorf("Unexpected method label in\n%s", body)
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// PanicReportKey is the context key for the report of a recovered panic
+const PanicReportKey ContextKey = "panicreport"
+
+// PanicReport describes a panic recovered while serving a request.
+type PanicReport struct {
+	// Value passed to panic
+	Value interface{}
+	// Stack trace of the panicking goroutine
+	Stack []byte
+	// Route is the pattern of the route matched by the router (req.Pattern),
+	// it's empty if the panic happens before the route matched.
+	Route string
+	// Method and Path of the request
+	Method string
+	Path   string
+	// Time of the recovery
+	Time time.Time
+}
+
+// PanicReporter receives the reports of recovered panics, e.g. to send them to an error tracker.
+// The request still holds the values of the router, e.g. trixie.GetCurrentRoute(r).
+type PanicReporter interface {
+	Report(r *http.Request, report PanicReport)
+}
+
+// PanicReporterFunc is an adapter to use functions as panic reporter.
+type PanicReporterFunc func(r *http.Request, report PanicReport)
+
+func (f PanicReporterFunc) Report(r *http.Request, report PanicReport) { f(r, report) }
+
+// RecoverOptions configures the Recover middleware.
+type RecoverOptions struct {
+	// Reporter receives the reports, the default reporter writes them to the standard logger.
+	Reporter PanicReporter
+	// Handler writes the response, the default handler answers 500 Internal Server Error.
+	// The report can be retrieved calling middleware.GetPanicReport(r)
+	Handler http.Handler
+}
+
+// Recover is a middleware which recovers panics of the next handlers.
+// It hands a report of the panic to the reporter and answers with the handler.
+// If the handler which panicked already wrote the header, the response is aborted
+// with http.ErrAbortHandler after the report.
+// A panic with http.ErrAbortHandler is propagated, it aborts the response on purpose.
+func Recover(options RecoverOptions) Middleware {
+	if options.Reporter == nil {
+		options.Reporter = PanicReporterFunc(logPanic)
+	}
+
+	if options.Handler == nil {
+		options.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		})
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := WrapResponseWriter(w)
+
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				report := PanicReport{
+					Value:  v,
+					Stack:  debug.Stack(),
+					Route:  routePattern(r),
+					Method: r.Method,
+					Path:   r.URL.Path,
+					Time:   time.Now(),
+				}
+
+				options.Reporter.Report(r, report)
+
+				// a response which is already on its way can't be replaced,
+				// the connection is aborted so the client doesn't take it as complete
+				if rw.Written() {
+					panic(http.ErrAbortHandler)
+				}
+
+				options.Handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), PanicReportKey, &report)))
+			}()
+
+			h.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// GetPanicReport returns the report of the recovered panic.
+// This only works when called inside the handler of the Recover middleware.
+func GetPanicReport(r *http.Request) *PanicReport {
+	if rv := r.Context().Value(PanicReportKey); rv != nil {
+		return rv.(*PanicReport)
+	}
+	return nil
+}
+
+func logPanic(r *http.Request, report PanicReport) {
+	log.Printf("panic serving %s %s (route %q): %v\n%s", report.Method, report.Path, report.Route, report.Value, report.Stack)
+}
@@ -0,0 +1,94 @@
+package middleware_test
+
+import (
+	"github.com/donutloop/trixie/middleware"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	var reports []middleware.PanicReport
+	reporter := middleware.PanicReporterFunc(func(r *http.Request, report middleware.PanicReport) {
+		reports = append(reports, report)
+	})
+
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Pattern = "/users/{id}"
+	rec := httptest.NewRecorder()
+	middleware.Recover(middleware.RecoverOptions{Reporter: reporter})(panicking).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Unexpected status code (Expected: %d, Actual: %d)", http.StatusInternalServerError, rec.Code)
+	}
+
+	if len(reports) != 1 {
+		t.Fatalf("Unexpected count of reports (Expected: 1, Actual: %d)", len(reports))
+	}
+
+	report := reports[0]
+	if report.Value != "boom" || report.Route != "/users/{id}" || report.Method != http.MethodGet || report.Path != "/users/1" {
+		t.Errorf("Unexpected report (%+v)", report)
+	}
+
+	if !strings.Contains(string(report.Stack), "recover_test.go") {
+		t.Errorf("Unexpected stack trace without panicking handler")
+	}
+
+	// the handler writes the response with the report
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(middleware.GetPanicReport(r).Value.(string)))
+	})
+
+	rec = httptest.NewRecorder()
+	middleware.Recover(middleware.RecoverOptions{Reporter: reporter, Handler: handler})(panicking).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable || rec.Body.String() != "boom" {
+		t.Errorf("Unexpected response (Status: %d, Body: %s)", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRecoverAbortHandler(t *testing.T) {
+	aborting := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("Unexpected recovered value (Expected: %v, Actual: %v)", http.ErrAbortHandler, v)
+		}
+	}()
+
+	middleware.Recover(middleware.RecoverOptions{})(aborting).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	t.Error("Expected a propagated panic")
+}
+
+func TestRecoverAbortsWrittenResponse(t *testing.T) {
+	partial := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		panic("boom")
+	})
+
+	var reported bool
+	reporter := middleware.PanicReporterFunc(func(r *http.Request, report middleware.PanicReport) {
+		reported = true
+	})
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("Unexpected recovered value (Expected: %v, Actual: %v)", http.ErrAbortHandler, v)
+		}
+		if !reported {
+			t.Error("Expected a report before the response is aborted")
+		}
+	}()
+
+	middleware.Recover(middleware.RecoverOptions{Reporter: reporter})(partial).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	t.Error("Expected an aborted response")
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+// RequestIDKey is the context key for the ID of a request
+const RequestIDKey ContextKey = "requestID"
+
+// DefaultRequestIDHeader is the header which carries the ID of a request
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// DefaultRequestIDMaxLength is the max length of an incoming request ID
+const DefaultRequestIDMaxLength = 128
+
+// RequestIDOptions configures the RequestID middleware.
+type RequestIDOptions struct {
+	// Header which carries the ID, X-Request-ID by default
+	Header string
+	// MaxLength of an incoming ID, DefaultRequestIDMaxLength by default
+	MaxLength int
+	// Validate reports whether an incoming ID is accepted, by default IDs of
+	// letters, digits and the characters - _ . : up to MaxLength are accepted
+	Validate func(id string) bool
+	// Generate returns a new ID, 32 random hex digits by default
+	Generate func() string
+}
+
+// RequestID is a middleware which assigns an ID to every request.
+//
+// An incoming ID is kept if it's valid, otherwise a new ID is generated.
+// The ID is stored in the request context and echoed in the response header.
+// It can be retrieved calling middleware.GetRequestID(r) or trixie.GetRequestID(r).
+//
+// Register it with router.UsePreRouting, so requests which don't match have an ID
+// as well and middleware like AccessLog and Trace can read it.
+func RequestID(options RequestIDOptions) Middleware {
+	if options.Header == "" {
+		options.Header = DefaultRequestIDHeader
+	}
+
+	if options.MaxLength <= 0 {
+		options.MaxLength = DefaultRequestIDMaxLength
+	}
+
+	if options.Validate == nil {
+		maxLength := options.MaxLength
+		options.Validate = func(id string) bool { return validRequestID(id, maxLength) }
+	}
+
+	if options.Generate == nil {
+		options.Generate = func() string { return randomHex(16) }
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(options.Header)
+			if id == "" || !options.Validate(id) {
+				id = options.Generate()
+			}
+
+			w.Header().Set(options.Header, id)
+			h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), RequestIDKey, id)))
+		})
+	}
+}
+
+// GetRequestID returns the ID of the request, it's empty if the request
+// isn't wrapped by the RequestID middleware.
+func GetRequestID(r *http.Request) string {
+	if rv := r.Context().Value(RequestIDKey); rv != nil {
+		return rv.(string)
+	}
+	return ""
+}
+
+// validRequestID reports whether the id consists of letters, digits and - _ . :
+// and isn't longer than maxLength, so it's safe to log and to echo.
+func validRequestID(id string, maxLength int) bool {
+	if len(id) > maxLength {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,129 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/donutloop/trixie"
+	"github.com/donutloop/trixie/middleware"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var id, routerID string
+	router := trixie.Classic()
+	router.UsePreRouting(middleware.RequestID(middleware.RequestIDOptions{MaxLength: 16}))
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id = middleware.GetRequestID(r)
+		routerID = trixie.GetRequestID(r)
+	})
+
+	testCases := []struct {
+		incoming string
+		kept     bool
+	}{
+		{incoming: "abc-123_x.y:z", kept: true},
+		{incoming: "0123456789abcdef", kept: true},
+		{incoming: "0123456789abcdefg"},
+		{incoming: "abc def"},
+		{incoming: "abcé"},
+		{incoming: ""},
+	}
+
+	for _, testCase := range testCases {
+		id, routerID = "", ""
+
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		if testCase.incoming != "" {
+			req.Header.Set("X-Request-ID", testCase.incoming)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if testCase.kept && id != testCase.incoming {
+			t.Errorf("%q: Unexpected request id (Expected: %s, Actual: %s)", testCase.incoming, testCase.incoming, id)
+		}
+		if !testCase.kept && (len(id) != 32 || id == testCase.incoming) {
+			t.Errorf("%q: Unexpected generated request id (%s)", testCase.incoming, id)
+		}
+		if routerID != id {
+			t.Errorf("%q: Unexpected id of trixie.GetRequestID (Expected: %s, Actual: %s)", testCase.incoming, id, routerID)
+		}
+		if echoed := rec.Header().Get("X-Request-ID"); echoed != id {
+			t.Errorf("%q: Unexpected echoed request id (Expected: %s, Actual: %s)", testCase.incoming, id, echoed)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	if rec.Code != http.StatusNotFound || len(rec.Header().Get("X-Request-ID")) != 32 {
+		t.Errorf("Unexpected response of unmatched request (Status: %d, Request ID: %q)", rec.Code, rec.Header().Get("X-Request-ID"))
+	}
+}
+
+func TestRequestIDOptions(t *testing.T) {
+	var id string
+	handler := middleware.RequestID(middleware.RequestIDOptions{
+		Header:   "X-Correlation-ID",
+		Validate: func(id string) bool { return strings.HasPrefix(id, "req-") },
+		Generate: func() string { return "req-new" },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = middleware.GetRequestID(r)
+	}))
+
+	testCases := []struct {
+		incoming string
+		id       string
+	}{
+		{incoming: "req-1", id: "req-1"},
+		{incoming: "1", id: "req-new"},
+		{incoming: "", id: "req-new"},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Correlation-ID", testCase.incoming)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if id != testCase.id || rec.Header().Get("X-Correlation-ID") != testCase.id {
+			t.Errorf("%q: Unexpected request id (Expected: %s, Actual: %s, Echoed: %s)", testCase.incoming, testCase.id, id, rec.Header().Get("X-Correlation-ID"))
+		}
+	}
+
+	if id := middleware.GetRequestID(httptest.NewRequest(http.MethodGet, "/", nil)); id != "" {
+		t.Errorf("Unexpected request id without middleware (%s)", id)
+	}
+}
+
+func TestRequestIDIsLoggedAndTraced(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exporter := &middleware.MemoryExporter{}
+
+	router := trixie.Classic()
+	router.UsePreRouting(middleware.RequestID(middleware.RequestIDOptions{}))
+	router.Use(middleware.AccessLog(middleware.AccessLogOptions{Logger: slog.New(slog.NewJSONHandler(buf, nil))}))
+	router.Use(middleware.Trace(middleware.TraceOptions{Exporter: exporter}))
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	id := rec.Header().Get("X-Request-ID")
+
+	var record struct {
+		RequestID string `json:"request_id"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.RequestID != id {
+		t.Errorf("Unexpected logged request id (Expected: %s, Actual: %s)", id, record.RequestID)
+	}
+
+	if spans := exporter.Spans(); len(spans) != 1 || spans[0].RequestID != id {
+		t.Errorf("Unexpected traced request id (Expected: %s, Actual: %v)", id, spans)
+	}
+}
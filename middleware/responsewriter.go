@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ResponseWriter is a http.ResponseWriter which tracks the response for middleware,
+// e.g. to log the status and size of a response.
+type ResponseWriter interface {
+	http.ResponseWriter
+	// Status returns the status code, 0 if the header isn't written yet
+	Status() int
+	// BytesWritten returns the size of the body written so far
+	BytesWritten() int64
+	// Written reports whether the header is written
+	Written() bool
+	// FirstByte returns the time the header was written, the zero time if it isn't written yet
+	FirstByte() time.Time
+	// Unwrap returns the underlying writer, it's used by http.ResponseController
+	Unwrap() http.ResponseWriter
+}
+
+// WrapResponseWriter wraps w into a ResponseWriter. The wrapper implements exactly the
+// optional interfaces w implements of http.Flusher, http.Hijacker, io.ReaderFrom and http.Pusher,
+// so type assertions of handlers behave the same for the wrapper and w.
+// A writer which already is a ResponseWriter is returned as it is.
+func WrapResponseWriter(w http.ResponseWriter) ResponseWriter {
+	if rw, ok := w.(ResponseWriter); ok {
+		return rw
+	}
+
+	rw := &responseWriter{ResponseWriter: w}
+
+	_, isFlusher := w.(http.Flusher)
+	_, isHijacker := w.(http.Hijacker)
+	_, isReaderFrom := w.(io.ReaderFrom)
+	_, isPusher := w.(http.Pusher)
+
+	f, h, rf, p := flusher{rw}, hijacker{rw}, readerFrom{rw}, pusher{rw}
+
+	switch {
+	case isFlusher && isHijacker && isReaderFrom && isPusher:
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			readerFrom
+			pusher
+		}{rw, f, h, rf, p}
+	case isFlusher && isHijacker && isReaderFrom:
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			readerFrom
+		}{rw, f, h, rf}
+	case isFlusher && isHijacker && isPusher:
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			pusher
+		}{rw, f, h, p}
+	case isFlusher && isReaderFrom && isPusher:
+		return struct {
+			*responseWriter
+			flusher
+			readerFrom
+			pusher
+		}{rw, f, rf, p}
+	case isHijacker && isReaderFrom && isPusher:
+		return struct {
+			*responseWriter
+			hijacker
+			readerFrom
+			pusher
+		}{rw, h, rf, p}
+	case isFlusher && isHijacker:
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+		}{rw, f, h}
+	case isFlusher && isReaderFrom:
+		return struct {
+			*responseWriter
+			flusher
+			readerFrom
+		}{rw, f, rf}
+	case isFlusher && isPusher:
+		return struct {
+			*responseWriter
+			flusher
+			pusher
+		}{rw, f, p}
+	case isHijacker && isReaderFrom:
+		return struct {
+			*responseWriter
+			hijacker
+			readerFrom
+		}{rw, h, rf}
+	case isHijacker && isPusher:
+		return struct {
+			*responseWriter
+			hijacker
+			pusher
+		}{rw, h, p}
+	case isReaderFrom && isPusher:
+		return struct {
+			*responseWriter
+			readerFrom
+			pusher
+		}{rw, rf, p}
+	case isFlusher:
+		return struct {
+			*responseWriter
+			flusher
+		}{rw, f}
+	case isHijacker:
+		return struct {
+			*responseWriter
+			hijacker
+		}{rw, h}
+	case isReaderFrom:
+		return struct {
+			*responseWriter
+			readerFrom
+		}{rw, rf}
+	case isPusher:
+		return struct {
+			*responseWriter
+			pusher
+		}{rw, p}
+	}
+
+	return rw
+}
+
+// responseWriter tracks the response, the optional interfaces are added by WrapResponseWriter
+type responseWriter struct {
+	http.ResponseWriter
+	status    int
+	bytes     int64
+	firstByte time.Time
+}
+
+func (rw *responseWriter) WriteHeader(status int) {
+	// informational headers (e.g. 103 Early Hints) can be followed by the final header
+	if !rw.Written() && status >= 200 {
+		rw.status = status
+		rw.firstByte = time.Now()
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.written()
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// written records the implicit header of a body written without WriteHeader
+func (rw *responseWriter) written() {
+	if !rw.Written() {
+		rw.status = http.StatusOK
+		rw.firstByte = time.Now()
+	}
+}
+
+func (rw *responseWriter) Status() int { return rw.status }
+
+func (rw *responseWriter) BytesWritten() int64 { return rw.bytes }
+
+func (rw *responseWriter) Written() bool { return rw.status != 0 }
+
+func (rw *responseWriter) FirstByte() time.Time { return rw.firstByte }
+
+func (rw *responseWriter) Unwrap() http.ResponseWriter { return rw.ResponseWriter }
+
+type flusher struct{ rw *responseWriter }
+
+func (f flusher) Flush() {
+	f.rw.written()
+	f.rw.ResponseWriter.(http.Flusher).Flush()
+}
+
+type hijacker struct{ rw *responseWriter }
+
+func (h hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return h.rw.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+type readerFrom struct{ rw *responseWriter }
+
+func (rf readerFrom) ReadFrom(r io.Reader) (int64, error) {
+	rf.rw.written()
+	n, err := rf.rw.ResponseWriter.(io.ReaderFrom).ReadFrom(r)
+	rf.rw.bytes += n
+	return n, err
+}
+
+type pusher struct{ rw *responseWriter }
+
+func (p pusher) Push(target string, opts *http.PushOptions) error {
+	return p.rw.ResponseWriter.(http.Pusher).Push(target, opts)
+}
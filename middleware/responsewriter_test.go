@@ -0,0 +1,95 @@
+package middleware_test
+
+import (
+	"bufio"
+	"github.com/donutloop/trixie/middleware"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type plainWriter struct{ http.ResponseWriter }
+
+type hijackWriter struct{ http.ResponseWriter }
+
+func (hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) { return nil, nil, nil }
+
+type deadlineWriter struct {
+	http.ResponseWriter
+	called bool
+}
+
+func (w *deadlineWriter) SetWriteDeadline(time.Time) error {
+	w.called = true
+	return nil
+}
+
+type fullWriter struct{ *httptest.ResponseRecorder }
+
+func (fullWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) { return nil, nil, nil }
+
+func (w fullWriter) ReadFrom(r io.Reader) (int64, error) { return io.Copy(w.ResponseRecorder, r) }
+
+func (fullWriter) Push(string, *http.PushOptions) error { return nil }
+
+func TestWrapResponseWriterInterfaces(t *testing.T) {
+	testCases := []struct {
+		name                                  string
+		writer                                http.ResponseWriter
+		flusher, hijacker, readerFrom, pusher bool
+	}{
+		{name: "plain", writer: plainWriter{httptest.NewRecorder()}},
+		{name: "recorder", writer: httptest.NewRecorder(), flusher: true},
+		{name: "hijacker", writer: hijackWriter{httptest.NewRecorder()}, hijacker: true},
+		{name: "full", writer: fullWriter{httptest.NewRecorder()}, flusher: true, hijacker: true, readerFrom: true, pusher: true},
+	}
+
+	for _, testCase := range testCases {
+		w := middleware.WrapResponseWriter(testCase.writer)
+
+		_, flusher := w.(http.Flusher)
+		_, hijacker := w.(http.Hijacker)
+		_, readerFrom := w.(io.ReaderFrom)
+		_, pusher := w.(http.Pusher)
+
+		if flusher != testCase.flusher || hijacker != testCase.hijacker || readerFrom != testCase.readerFrom || pusher != testCase.pusher {
+			t.Errorf("%s: Unexpected interfaces (Flusher: %t, Hijacker: %t, ReaderFrom: %t, Pusher: %t)", testCase.name, flusher, hijacker, readerFrom, pusher)
+		}
+
+		if w.Unwrap() != testCase.writer {
+			t.Errorf("%s: Unexpected unwrapped writer", testCase.name)
+		}
+	}
+}
+
+func TestWrapResponseWriterTracking(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := middleware.WrapResponseWriter(fullWriter{rec})
+
+	if w.Written() || w.Status() != 0 || !w.FirstByte().IsZero() {
+		t.Errorf("Unexpected written response (Status: %d)", w.Status())
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write([]byte("hello "))
+	w.(io.ReaderFrom).ReadFrom(strings.NewReader("world"))
+
+	if !w.Written() || w.Status() != http.StatusAccepted || w.BytesWritten() != 11 || w.FirstByte().IsZero() {
+		t.Errorf("Unexpected tracked response (Status: %d, Bytes: %d)", w.Status(), w.BytesWritten())
+	}
+
+	if middleware.WrapResponseWriter(w) != w {
+		t.Errorf("Unexpected wrapped wrapper")
+	}
+
+	// the response controller finds the methods of the underlying writer
+	dw := &deadlineWriter{ResponseWriter: rec}
+	if err := http.NewResponseController(middleware.WrapResponseWriter(dw)).SetWriteDeadline(time.Now()); err != nil || !dw.called {
+		t.Errorf("Unexpected error of the response controller (%v)", err)
+	}
+}
@@ -0,0 +1,245 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanKey is the context key for the span of a request
+const SpanKey ContextKey = "span"
+
+// Span is the server side of a traced request, as defined by W3C Trace Context.
+type Span struct {
+	// Name is the method and the route pattern, e.g. GET /users/{id}
+	Name         string    `json:"name"`
+	TraceID      string    `json:"trace_id"`
+	SpanID       string    `json:"span_id"`
+	ParentSpanID string    `json:"parent_span_id,omitempty"`
+	TraceState   string    `json:"trace_state,omitempty"`
+	Sampled      bool      `json:"sampled"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	Route        string    `json:"route,omitempty"`
+	RequestID    string    `json:"request_id,omitempty"`
+	Status       int       `json:"status"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+}
+
+// TraceParent returns the traceparent header for calls to other services,
+// the span is the parent of their spans.
+func (s *Span) TraceParent() string {
+	flags := "00"
+	if s.Sampled {
+		flags = "01"
+	}
+	return "00-" + s.TraceID + "-" + s.SpanID + "-" + flags
+}
+
+// Inject sets the traceparent and tracestate headers of an outgoing request
+func (s *Span) Inject(header http.Header) {
+	header.Set("traceparent", s.TraceParent())
+	if s.TraceState != "" {
+		header.Set("tracestate", s.TraceState)
+	}
+}
+
+// SpanExporter receives the finished spans which are sampled.
+type SpanExporter interface {
+	Export(span Span) error
+}
+
+// MemoryExporter keeps the spans in memory, e.g. for tests.
+type MemoryExporter struct {
+	mu    sync.Mutex
+	spans []Span
+}
+
+func (e *MemoryExporter) Export(span Span) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+	return nil
+}
+
+// Spans returns a copy of the exported spans
+func (e *MemoryExporter) Spans() []Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Span(nil), e.spans...)
+}
+
+// Reset removes the exported spans
+func (e *MemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+// JSONLinesExporter writes every span as a JSON object on its own line.
+type JSONLinesExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLinesExporter returns an exporter which writes to w
+func NewJSONLinesExporter(w io.Writer) *JSONLinesExporter {
+	return &JSONLinesExporter{w: w}
+}
+
+// NewJSONLinesFileExporter returns an exporter which appends to the file,
+// it's created if it doesn't exist. The file is closed calling Close.
+func NewJSONLinesFileExporter(name string) (*JSONLinesExporter, error) {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONLinesExporter(f), nil
+}
+
+func (e *JSONLinesExporter) Export(span Span) error {
+	line, err := json.Marshal(span)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	_, err = e.w.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the underlying writer if it's an io.Closer
+func (e *JSONLinesExporter) Close() error {
+	if closer, ok := e.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// TraceOptions configures the Trace middleware.
+type TraceOptions struct {
+	// Exporter receives the sampled spans
+	Exporter SpanExporter
+	// OnError is called if the export fails, the error is logged by default
+	OnError func(error)
+}
+
+// Trace is a middleware which creates a span per request and passes it to the exporter.
+//
+// A request with a valid traceparent header continues the trace of the caller,
+// its tracestate is kept. Otherwise a new sampled trace is started.
+// The span can be retrieved calling middleware.GetSpan(r), e.g. to propagate
+// the trace to other services with span.Inject(outgoing.Header).
+//
+// The span is named after the route pattern (req.Pattern or the Match for pre-routing
+// middleware), so it can be registered with router.Use or router.UsePreRouting.
+func Trace(options TraceOptions) Middleware {
+	if options.OnError == nil {
+		options.OnError = func(err error) { log.Printf("trace export: %s", err.Error()) }
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			span := &Span{
+				SpanID:    randomHex(8),
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				RequestID: GetRequestID(r),
+				Start:     time.Now(),
+			}
+
+			if traceID, parentID, sampled, ok := parseTraceParent(r.Header.Get("traceparent")); ok {
+				span.TraceID, span.ParentSpanID, span.Sampled = traceID, parentID, sampled
+				span.TraceState = r.Header.Get("tracestate")
+			} else {
+				span.TraceID, span.Sampled = randomHex(16), true
+			}
+
+			rw := WrapResponseWriter(w)
+			h.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), SpanKey, span)))
+
+			span.End = time.Now()
+			span.Route = routePattern(r)
+			span.Name = strings.TrimSpace(r.Method + " " + span.Route)
+			span.Status = rw.Status()
+			if span.Status == 0 {
+				span.Status = http.StatusOK
+			}
+
+			if span.Sampled && options.Exporter != nil {
+				if err := options.Exporter.Export(*span); err != nil {
+					options.OnError(err)
+				}
+			}
+		})
+	}
+}
+
+// GetSpan returns the span of the request.
+// This only works when called inside a handler wrapped by the Trace middleware.
+func GetSpan(r *http.Request) *Span {
+	if rv := r.Context().Value(SpanKey); rv != nil {
+		return rv.(*Span)
+	}
+	return nil
+}
+
+// parseTraceParent parses a traceparent header like
+//
+//	00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+//
+// Future versions are parsed like version 00, as the specification demands.
+func parseTraceParent(header string) (traceID, parentID string, sampled bool, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return "", "", false, false
+	}
+
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	if !isHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return "", "", false, false
+	}
+
+	if !isHex(traceID, 32) || traceID == strings.Repeat("0", 32) || !isHex(parentID, 16) || parentID == strings.Repeat("0", 16) || !isHex(flags, 2) {
+		return "", "", false, false
+	}
+
+	var f [1]byte
+	hex.Decode(f[:], []byte(flags))
+
+	return traceID, parentID, f[0]&0x01 == 0x01, true
+}
+
+// isHex reports whether s consists of n lower case hex digits
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("trace: can't generate id: %s", err.Error()))
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,189 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/donutloop/trixie"
+	"github.com/donutloop/trixie/middleware"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	exporter := &middleware.MemoryExporter{}
+
+	var span *middleware.Span
+	router := trixie.Classic()
+	router.Use(middleware.Trace(middleware.TraceOptions{Exporter: exporter}))
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		span = middleware.GetSpan(r)
+		if r.PathValue("id") == "0" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	testCases := []struct {
+		path        string
+		traceparent string
+		tracestate  string
+		traceID     string
+		parentID    string
+		sampled     bool
+		exported    bool
+		status      int
+	}{
+		{path: "/users/1", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", tracestate: "congo=t61rcWkgMzE", traceID: "4bf92f3577b34da6a3ce929d0e0e4736", parentID: "00f067aa0ba902b7", sampled: true, exported: true, status: http.StatusOK},
+		{path: "/users/0", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", traceID: "4bf92f3577b34da6a3ce929d0e0e4736", parentID: "00f067aa0ba902b7", status: http.StatusNotFound},
+		{path: "/users/2", traceparent: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future", traceID: "4bf92f3577b34da6a3ce929d0e0e4736", parentID: "00f067aa0ba902b7", sampled: true, exported: true, status: http.StatusOK},
+		{path: "/users/3", sampled: true, exported: true, status: http.StatusOK},
+		{path: "/users/4", traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", sampled: true, exported: true, status: http.StatusOK},
+		{path: "/users/5", traceparent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", sampled: true, exported: true, status: http.StatusOK},
+		{path: "/users/6", traceparent: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sampled: true, exported: true, status: http.StatusOK},
+	}
+
+	for _, testCase := range testCases {
+		exporter.Reset()
+		span = nil
+
+		req := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+		if testCase.traceparent != "" {
+			req.Header.Set("traceparent", testCase.traceparent)
+		}
+		if testCase.tracestate != "" {
+			req.Header.Set("tracestate", testCase.tracestate)
+		}
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		if span == nil {
+			t.Errorf("%s: Unexpected missing span", testCase.path)
+			continue
+		}
+
+		if testCase.traceID != "" && span.TraceID != testCase.traceID {
+			t.Errorf("%s: Unexpected trace id (Expected: %s, Actual: %s)", testCase.path, testCase.traceID, span.TraceID)
+		}
+		if testCase.traceID == "" && (len(span.TraceID) != 32 || span.TraceID == "4bf92f3577b34da6a3ce929d0e0e4736") {
+			t.Errorf("%s: Unexpected trace id (%s)", testCase.path, span.TraceID)
+		}
+		if span.ParentSpanID != testCase.parentID {
+			t.Errorf("%s: Unexpected parent span id (Expected: %q, Actual: %q)", testCase.path, testCase.parentID, span.ParentSpanID)
+		}
+		if len(span.SpanID) != 16 || span.SpanID == testCase.parentID {
+			t.Errorf("%s: Unexpected span id (%s)", testCase.path, span.SpanID)
+		}
+		if span.TraceState != testCase.tracestate {
+			t.Errorf("%s: Unexpected trace state (Expected: %q, Actual: %q)", testCase.path, testCase.tracestate, span.TraceState)
+		}
+		if span.Sampled != testCase.sampled {
+			t.Errorf("%s: Unexpected sampled flag (Expected: %v, Actual: %v)", testCase.path, testCase.sampled, span.Sampled)
+		}
+
+		spans := exporter.Spans()
+		if exported := len(spans) == 1; exported != testCase.exported {
+			t.Errorf("%s: Unexpected export (Expected: %v, Actual: %v)", testCase.path, testCase.exported, exported)
+		}
+
+		if span.Status != testCase.status {
+			t.Errorf("%s: Unexpected status (Expected: %d, Actual: %d)", testCase.path, testCase.status, span.Status)
+		}
+		if span.Name != "GET /users/{id}" || span.Route != "/users/{id}" || span.Path != testCase.path {
+			t.Errorf("%s: Unexpected name, route or path (%s, %s, %s)", testCase.path, span.Name, span.Route, span.Path)
+		}
+		if span.End.Before(span.Start) {
+			t.Errorf("%s: Unexpected timing (Start: %v, End: %v)", testCase.path, span.Start, span.End)
+		}
+	}
+}
+
+func TestSpanInject(t *testing.T) {
+	span := &middleware.Span{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", TraceState: "congo=t61rcWkgMzE", Sampled: true}
+
+	header := http.Header{}
+	span.Inject(header)
+
+	if traceparent := header.Get("traceparent"); traceparent != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("Unexpected traceparent (%s)", traceparent)
+	}
+	if tracestate := header.Get("tracestate"); tracestate != "congo=t61rcWkgMzE" {
+		t.Errorf("Unexpected tracestate (%s)", tracestate)
+	}
+
+	span.Sampled = false
+	if traceparent := span.TraceParent(); !strings.HasSuffix(traceparent, "-00") {
+		t.Errorf("Unexpected traceparent (%s)", traceparent)
+	}
+}
+
+func TestJSONLinesExporter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exporter := middleware.NewJSONLinesExporter(buf)
+
+	handler := middleware.Trace(middleware.TraceOptions{Exporter: exporter})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users", nil))
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Unexpected count of lines (Expected: 2, Actual: %d)", len(lines))
+	}
+
+	var span middleware.Span
+	if err := json.Unmarshal([]byte(lines[0]), &span); err != nil {
+		t.Fatal(err)
+	}
+
+	if span.Name != "POST" || span.Status != http.StatusCreated || span.Path != "/users" || len(span.TraceID) != 32 {
+		t.Errorf("Unexpected span (%s)", lines[0])
+	}
+
+	name := filepath.Join(t.TempDir(), "spans.jsonl")
+	fileExporter, err := middleware.NewJSONLinesFileExporter(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fileExporter.Export(span); err != nil {
+		t.Fatal(err)
+	}
+	if err := fileExporter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != lines[0]+"\n" {
+		t.Errorf("Unexpected file content (Expected: %s, Actual: %s)", lines[0], data)
+	}
+}
+
+func TestTracePreRouting(t *testing.T) {
+	exporter := &middleware.MemoryExporter{}
+
+	router := trixie.Classic()
+	router.UsePreRouting(middleware.Trace(middleware.TraceOptions{Exporter: exporter}))
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Unexpected count of spans (Expected: 2, Actual: %d)", len(spans))
+	}
+
+	if spans[0].Name != "GET /users/{id}" || spans[0].Route != "/users/{id}" {
+		t.Errorf("Unexpected span of a matched request (Name: %s, Route: %s)", spans[0].Name, spans[0].Route)
+	}
+	if spans[1].Name != "GET" || spans[1].Status != http.StatusNotFound {
+		t.Errorf("Unexpected span of a miss (Name: %s, Status: %d)", spans[1].Name, spans[1].Status)
+	}
+}
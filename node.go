@@ -1,5 +1,7 @@
 package trixie
 
+import "regexp"
+
 type nodeType int
 
 // All kind of Nodes
@@ -7,15 +9,35 @@ const (
 	staticNode nodeType = iota
 	paramNode
 	regexNode
+	catchAllNode
+	mixedNode
 	nodeTypes
 )
 
+func (typ nodeType) String() string {
+	switch typ {
+	case staticNode:
+		return "static"
+	case paramNode:
+		return "param"
+	case regexNode:
+		return "regex"
+	case catchAllNode:
+		return "catch-all"
+	case mixedNode:
+		return "mixed"
+	}
+	return "unknown"
+}
+
 // NewNode creates a Node instance and setup place for sub nodes
 func NewNode() *Node {
 	nodes := [nodeTypes][]*Node{}
 	nodes[staticNode] = make([]*Node, 0, 0)
 	nodes[paramNode] = make([]*Node, 0, 0)
 	nodes[regexNode] = make([]*Node, 0, 0)
+	nodes[catchAllNode] = make([]*Node, 0, 0)
+	nodes[mixedNode] = make([]*Node, 0, 0)
 	return &Node{
 		nodes: nodes,
 	}
@@ -35,4 +57,25 @@ type Node struct {
 
 	// Segment of an path
 	seg string
+
+	// Type of the segment
+	typ nodeType
+
+	// Name of the parameter, empty if the segment is unnamed
+	name string
+
+	// Name of the segment type of a param node (e.g. number)
+	kind string
+
+	// Matcher of a param node
+	matcher SegmentMatcher
+
+	// Compiled expression of a regex node
+	regexp *regexp.Regexp
+
+	// Literals and captures of a mixed node
+	parts []segmentPart
+
+	// Defaults of the optional params which are absent if a path ends at the node
+	defaults map[string]string
 }
@@ -0,0 +1,184 @@
+package trixie
+
+import (
+	"strings"
+)
+
+// optionalPattern is a pattern with optional trailing segments:
+//
+//	/posts/{page:number?}     page is optional
+//	/posts/{page:number=1}    page is optional and defaults to 1
+//	/reports[/{format}]       the bracket group is optional
+//	/reports[/{format=json}[/{lang}]]
+//
+// All segments after the first optional one have to be optional as well.
+type optionalPattern struct {
+	// base is the part of the pattern which is always present
+	base string
+	// groups are the optional parts, a group is only present
+	// if all groups before are present
+	groups []string
+	// defaults are the values of absent optional params by name
+	defaults map[string]string
+}
+
+// parseOptionalPattern splits a pattern into base and optional groups and
+// removes the markers (? and =default) of optional params.
+func parseOptionalPattern(pattern string) (optionalPattern, error) {
+	op := optionalPattern{defaults: map[string]string{}}
+
+	// [/ can't be part of a regex segment, because segments don't contain a slash
+	brackets := make([]string, 0)
+	if i := strings.Index(pattern, "[/"); i >= 0 {
+		groups := strings.Split(pattern[i+1:], "[/")
+		last := groups[len(groups)-1]
+
+		if !strings.HasSuffix(last, strings.Repeat("]", len(groups))) {
+			return op, NewBadPathError("Pattern " + pattern + " has unbalanced optional groups")
+		}
+		groups[len(groups)-1] = last[:len(last)-len(groups)]
+		if strings.Contains(strings.Join(groups, ""), "]") {
+			return op, NewBadPathError("Pattern " + pattern + " has unbalanced optional groups")
+		}
+
+		for k, group := range groups {
+			if k > 0 {
+				group = "/" + group
+			}
+
+			segments := strings.Split(group, "/")
+			for j, seg := range segments {
+				segments[j] = op.stripSegment(seg)
+			}
+			brackets = append(brackets, strings.Join(segments, "/"))
+		}
+
+		pattern = pattern[:i]
+	}
+
+	base := make([]string, 0)
+	segments := strings.Split(pattern, "/")
+	for k, seg := range segments {
+		// a trailing slash doesn't count as segment
+		if seg == "" && k == len(segments)-1 && len(op.groups) > 0 {
+			break
+		}
+
+		if _, optional, _, _ := parseOptionalSegment(seg); optional {
+			op.groups = append(op.groups, "/"+op.stripSegment(seg))
+			continue
+		}
+
+		if len(op.groups) > 0 {
+			return op, NewBadPathError("Pattern " + pattern + " has a required segment after an optional one")
+		}
+		base = append(base, seg)
+	}
+
+	if len(op.groups) > 0 && len(brackets) > 0 {
+		return op, NewBadPathError("Pattern " + pattern + " mixes optional segments and optional groups")
+	}
+	op.groups = append(op.groups, brackets...)
+
+	op.base = strings.Join(base, "/")
+	if op.base == "" {
+		op.base = "/"
+	}
+
+	return op, nil
+}
+
+// stripSegment removes the marker of an optional param segment and keeps its default
+func (op optionalPattern) stripSegment(seg string) string {
+	stripped, _, name, def := parseOptionalSegment(seg)
+	if name != "" && def != "" {
+		op.defaults[name] = def
+	}
+	return stripped
+}
+
+// parseOptionalSegment removes the marker of an optional param segment
+// ({name?}, {name:type?}, {name=default} or {name:type=default}).
+func parseOptionalSegment(seg string) (stripped string, optional bool, name, def string) {
+	if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") || strings.Count(seg, "{") != 1 {
+		return seg, false, "", ""
+	}
+
+	inner := seg[1 : len(seg)-1]
+
+	// the constraint of a type may contain a =, e.g. {code:string(len=3)}
+	if i := strings.Index(inner[strings.LastIndex(inner, ")")+1:], "="); i >= 0 {
+		i += strings.LastIndex(inner, ")") + 1
+		inner, def, optional = inner[:i], inner[i+1:], true
+	} else if strings.HasSuffix(inner, "?") {
+		inner, optional = inner[:len(inner)-1], true
+	}
+
+	name = inner
+	if i := strings.Index(name, ":"); i >= 0 {
+		name = name[:i]
+	}
+
+	return "{" + inner + "}", optional, name, def
+}
+
+// expandPattern returns the patterns an optional pattern stands for,
+// from the shortest to the longest. A pattern without optional segments
+// expands to itself.
+func expandPattern(pattern string) ([]string, error) {
+	op, err := parseOptionalPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(op.groups) == 0 {
+		return []string{pattern}, nil
+	}
+
+	return op.expand(), nil
+}
+
+func (op optionalPattern) expand() []string {
+	patterns := []string{op.base}
+	current := strings.TrimSuffix(op.base, "/")
+	for _, group := range op.groups {
+		current += group
+		patterns = append(patterns, current)
+	}
+	return patterns
+}
+
+// absentDefaults returns the defaults of the params which are absent in the expanded pattern
+func (op optionalPattern) absentDefaults(pattern string) map[string]string {
+	if len(op.defaults) == 0 {
+		return nil
+	}
+
+	defaults := make(map[string]string, len(op.defaults))
+	for name, def := range op.defaults {
+		defaults[name] = def
+	}
+
+	for _, seg := range strings.Split(pattern, "/") {
+		_, name, _ := parseSegment(seg)
+		delete(defaults, name)
+	}
+
+	return defaults
+}
+
+// fullPattern returns the longest expansion of a pattern, the pattern
+// with all optional segments and without markers.
+func fullPattern(pattern string) string {
+	patterns, err := expandPattern(pattern)
+	if err != nil {
+		return pattern
+	}
+	return patterns[len(patterns)-1]
+}
+
+// hasOptionalSegments reports whether the pattern has optional segments
+func hasOptionalSegments(pattern string) bool {
+	op, err := parseOptionalPattern(pattern)
+	return err == nil && len(op.groups) > 0
+}
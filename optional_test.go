@@ -0,0 +1,172 @@
+package trixie
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExpandPattern(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		patterns []string
+	}{
+		{pattern: "/posts", patterns: []string{"/posts"}},
+		{pattern: "/posts/{page?}", patterns: []string{"/posts", "/posts/{page}"}},
+		{pattern: "/posts/{page:int64=1}", patterns: []string{"/posts", "/posts/{page:int64}"}},
+		{pattern: "/{page?}", patterns: []string{"/", "/{page}"}},
+		{pattern: "/reports[/{format}[/{lang}]]", patterns: []string{"/reports", "/reports/{format}", "/reports/{format}/{lang}"}},
+		{pattern: "/reports[/{format=json}/raw]", patterns: []string{"/reports", "/reports/{format}/raw"}},
+	}
+
+	for _, testCase := range testCases {
+		patterns, err := expandPattern(testCase.pattern)
+		if err != nil {
+			t.Errorf("%s: Unexpected error (%s)", testCase.pattern, err.Error())
+			continue
+		}
+
+		if len(patterns) != len(testCase.patterns) {
+			t.Errorf("%s: Unexpected expansions (Expected: %v, Actual: %v)", testCase.pattern, testCase.patterns, patterns)
+			continue
+		}
+
+		for i := range patterns {
+			if patterns[i] != testCase.patterns[i] {
+				t.Errorf("%s: Unexpected expansions (Expected: %v, Actual: %v)", testCase.pattern, testCase.patterns, patterns)
+				break
+			}
+		}
+	}
+
+	for _, pattern := range []string{"/posts/{page?}/comments", "/reports[/{format}", "/reports[/{format}]]", "/posts/{page?}[/{format}]"} {
+		if _, err := expandPattern(pattern); err == nil {
+			t.Errorf("Expected an error for pattern %s", pattern)
+		}
+	}
+}
+
+func TestRouterOptionalSegments(t *testing.T) {
+	router := Classic()
+
+	var params map[string]string
+	var values map[string]interface{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		params = GetRouteParameters(r)
+		values = GetRouteValues(r)
+	}
+
+	router.Get("/posts/{page:int64=1}", handler)
+	router.Get("/users/{id?}", handler)
+	router.Get("/reports[/{format=json}[/{lang}]]", handler)
+
+	testCases := []struct {
+		path   string
+		params map[string]string
+	}{
+		{path: "/posts", params: map[string]string{"page": "1"}},
+		{path: "/posts/3", params: map[string]string{"page": "3"}},
+		{path: "/users", params: map[string]string{}},
+		{path: "/users/7", params: map[string]string{"id": "7"}},
+		{path: "/reports", params: map[string]string{"format": "json"}},
+		{path: "/reports/csv", params: map[string]string{"format": "csv"}},
+		{path: "/reports/csv/de", params: map[string]string{"format": "csv", "lang": "de"}},
+	}
+
+	for _, testCase := range testCases {
+		params = nil
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, testCase.path, nil))
+
+		if params == nil {
+			t.Errorf("Unexpected miss of %s", testCase.path)
+			continue
+		}
+
+		for name, value := range testCase.params {
+			if params[name] != value {
+				t.Errorf("%s: Unexpected parameter %s (Expected: %s, Actual: %s)", testCase.path, name, value, params[name])
+			}
+		}
+	}
+
+	if _, found := params["lang"]; !found {
+		t.Errorf("Unexpected missing parameter lang")
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts", nil))
+	if values["page"] != int64(1) {
+		t.Errorf("Unexpected converted default page (%v)", values["page"])
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	if _, found := params["id"]; found {
+		t.Errorf("Unexpected parameter id without default (%s)", params["id"])
+	}
+
+	if _, err := router.Lookup(http.MethodGet, "/posts/3/4"); err != ErrNotFound {
+		t.Errorf("Unexpected match of /posts/3/4")
+	}
+}
+
+func TestRouterOptionalSegmentsConflict(t *testing.T) {
+	router := Classic()
+	router.Get("/users/{id?}", nil)
+
+	// the same pattern is merged
+	router.Post("/users/{id?}", nil)
+
+	match, err := router.Lookup(http.MethodPost, "/users")
+	if err != nil || match.Route.GetPattern() != "/users/{id?}" {
+		t.Errorf("Unexpected lookup of merged route (%v)", err)
+	}
+
+	for _, pattern := range []string{"/users", "/users/{name=me}"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for pattern %s", pattern)
+				}
+			}()
+			router.Get(pattern, nil)
+		}()
+	}
+}
+
+func TestRouterOptionalSegmentsConflictLeavesTreeUnchanged(t *testing.T) {
+	router := Classic()
+	router.Get("/p/{x}", func(w http.ResponseWriter, r *http.Request) {})
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected a panic for pattern /p[/{x}]")
+			}
+		}()
+		router.Get("/p[/{x}]", func(w http.ResponseWriter, r *http.Request) {})
+	}()
+
+	if _, err := router.Lookup(http.MethodGet, "/p"); err != ErrNotFound {
+		t.Errorf("Unexpected lookup of a conflicting expansion (Expected: %v, Actual: %v)", ErrNotFound, err)
+	}
+}
+
+func TestRouterOptionalSegmentsDefaults(t *testing.T) {
+	router := Classic()
+	router.RegisterSegmentType("lang", SegmentType{Validate: func(seg string) bool { return seg == "en" || seg == "de" }})
+
+	for _, pattern := range []string{"/a/{page:number(1..5)=3}", "/b[/{lang:lang=en}]", "/c/{code:string(len=2)=de}"} {
+		router.Get(pattern, nil)
+	}
+
+	for _, pattern := range []string{"/p/{page:number=abc}", "/q/{page:number(1..5)=9}", "/r[/{lang:lang=fr}]", "/s/{code:string(len=2)=deu}"} {
+		func() {
+			defer func() {
+				if err := recover(); err == nil || !strings.Contains(err.(string), "default") {
+					t.Errorf("Expected a panic for the default of pattern %s (%v)", pattern, err)
+				}
+			}()
+			router.Get(pattern, nil)
+		}()
+	}
+}
@@ -0,0 +1,332 @@
+package trixie
+
+import (
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrParamMissing is the cause of a ParamError if the route has no such parameter.
+var ErrParamMissing = errors.New("parameter is missing")
+
+// ErrParamUnexported is the cause of a ParamError if the param tag is on an unexported field.
+var ErrParamUnexported = errors.New("field is unexported")
+
+// ParamError is returned if a route parameter can't be converted.
+type ParamError struct {
+	// Name of the parameter
+	Name string
+	// Value of the parameter
+	Value string
+	// Type the value should be converted to
+	Type string
+	// Err is the cause of the failed conversion
+	Err error
+}
+
+func (pe *ParamError) Error() string {
+	if pe.Err == ErrParamMissing {
+		return fmt.Sprintf("parameter %s is missing", pe.Name)
+	}
+	if pe.Err == ErrParamUnexported {
+		return fmt.Sprintf("parameter %s can't be bound to an unexported field", pe.Name)
+	}
+	return fmt.Sprintf("parameter %s (%q) is not a valid %s", pe.Name, pe.Value, pe.Type)
+}
+
+func (pe *ParamError) Unwrap() error { return pe.Err }
+
+// Param returns the route parameter by name and if it exists.
+func Param(r *http.Request, name string) (string, bool) {
+	value, found := GetRouteParameters(r)[name]
+	return value, found
+}
+
+// ParamInt returns the route parameter by name as int
+func ParamInt(r *http.Request, name string) (int, error) {
+	v, err := parseParam(r, name, "int", func(value string) (interface{}, error) {
+		return strconv.Atoi(value)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(int), nil
+}
+
+// ParamInt64 returns the route parameter by name as int64
+func ParamInt64(r *http.Request, name string) (int64, error) {
+	v, err := parseParam(r, name, "int64", func(value string) (interface{}, error) {
+		return strconv.ParseInt(value, 10, 64)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(int64), nil
+}
+
+// ParamUint64 returns the route parameter by name as uint64
+func ParamUint64(r *http.Request, name string) (uint64, error) {
+	v, err := parseParam(r, name, "uint64", func(value string) (interface{}, error) {
+		return strconv.ParseUint(value, 10, 64)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(uint64), nil
+}
+
+// ParamFloat64 returns the route parameter by name as float64
+func ParamFloat64(r *http.Request, name string) (float64, error) {
+	v, err := parseParam(r, name, "float64", func(value string) (interface{}, error) {
+		return strconv.ParseFloat(value, 64)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(float64), nil
+}
+
+// ParamBool returns the route parameter by name as bool
+func ParamBool(r *http.Request, name string) (bool, error) {
+	v, err := parseParam(r, name, "bool", func(value string) (interface{}, error) {
+		return strconv.ParseBool(value)
+	})
+	if err != nil {
+		return false, err
+	}
+	return v.(bool), nil
+}
+
+// ParamUUID returns the route parameter by name as UUID
+func ParamUUID(r *http.Request, name string) (UUID, error) {
+	v, err := parseParam(r, name, "uuid", func(value string) (interface{}, error) {
+		return ParseUUID(value)
+	})
+	if err != nil {
+		return UUID{}, err
+	}
+	return v.(UUID), nil
+}
+
+// ParamTime returns the route parameter by name as time, parsed with the given layout
+func ParamTime(r *http.Request, name string, layout string) (time.Time, error) {
+	v, err := parseParam(r, name, "time", func(value string) (interface{}, error) {
+		return time.Parse(layout, value)
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return v.(time.Time), nil
+}
+
+func parseParam(r *http.Request, name string, typ string, parse func(string) (interface{}, error)) (interface{}, error) {
+	value, found := Param(r, name)
+	if !found {
+		return nil, &ParamError{Name: name, Type: typ, Err: ErrParamMissing}
+	}
+
+	v, err := parse(value)
+	if err != nil {
+		return nil, &ParamError{Name: name, Value: value, Type: typ, Err: err}
+	}
+
+	return v, nil
+}
+
+// UUID is a universally unique identifier as defined in RFC 4122
+type UUID [16]byte
+
+// ParseUUID parses the canonical form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+func ParseUUID(s string) (UUID, error) {
+	var uuid UUID
+
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return uuid, errors.New("invalid uuid format")
+	}
+
+	digits := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	if _, err := hex.Decode(uuid[:], []byte(digits)); err != nil {
+		return uuid, err
+	}
+
+	return uuid, nil
+}
+
+func (u UUID) String() string {
+	s := hex.EncodeToString(u[:])
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so a UUID can be bound.
+func (u *UUID) UnmarshalText(text []byte) error {
+	uuid, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = uuid
+	return nil
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// BindParams copies the route parameters into the fields of the struct v points to.
+// The name of the parameter is taken from the param tag of a field:
+//
+//	var params struct {
+//	    ID      int64     `param:"id"`
+//	    Day     time.Time `param:"day,layout=2006-01-02"`
+//	    Comment string    `param:"comment,optional"`
+//	}
+//	err := trixie.BindParams(req, &params)
+//
+// Strings, bools, ints, uints, floats, time.Time (RFC 3339 by default) and all
+// types implementing encoding.TextUnmarshaler are supported. A missing parameter
+// is an error unless the field is optional. The returned error is a *ParamError.
+func BindParams(r *http.Request, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("bind params: expects a pointer to a struct")
+	}
+
+	params := GetRouteParameters(r)
+	rv = rv.Elem()
+
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+
+		tag, found := field.Tag.Lookup("param")
+		if !found || tag == "-" {
+			continue
+		}
+
+		name, options := parseParamTag(tag)
+		if !rv.Field(i).CanSet() {
+			return &ParamError{Name: name, Type: field.Type.String(), Err: ErrParamUnexported}
+		}
+
+		value, found := params[name]
+		if !found {
+			if _, optional := options["optional"]; optional {
+				continue
+			}
+			return &ParamError{Name: name, Type: field.Type.String(), Err: ErrParamMissing}
+		}
+
+		if err := setParam(rv.Field(i), value, options); err != nil {
+			return &ParamError{Name: name, Value: value, Type: field.Type.String(), Err: err}
+		}
+	}
+
+	return nil
+}
+
+// BindParamsOrError binds the route parameters like BindParams.
+// If the binding fails it answers the request with 400 Bad Request
+// and a structured error (see WriteParamError) and returns false.
+func BindParamsOrError(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := BindParams(r, v); err != nil {
+		WriteParamError(w, err)
+		return false
+	}
+	return true
+}
+
+// WriteParamError answers with 400 Bad Request and a JSON body describing the error:
+//
+//	{"error":"parameter id (\"abc\") is not a valid int64","parameter":"id","value":"abc","type":"int64"}
+func WriteParamError(w http.ResponseWriter, err error) {
+	body := struct {
+		Error     string `json:"error"`
+		Parameter string `json:"parameter,omitempty"`
+		Value     string `json:"value,omitempty"`
+		Type      string `json:"type,omitempty"`
+	}{
+		Error: err.Error(),
+	}
+
+	var paramErr *ParamError
+	if errors.As(err, &paramErr) {
+		body.Parameter = paramErr.Name
+		body.Value = paramErr.Value
+		body.Type = paramErr.Type
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(body)
+}
+
+func parseParamTag(tag string) (string, map[string]string) {
+	parts := strings.Split(tag, ",")
+	options := make(map[string]string, len(parts)-1)
+
+	for _, option := range parts[1:] {
+		kv := strings.SplitN(option, "=", 2)
+		if len(kv) == 2 {
+			options[kv[0]] = kv[1]
+		} else {
+			options[kv[0]] = ""
+		}
+	}
+
+	return parts[0], options
+}
+
+func setParam(field reflect.Value, value string, options map[string]string) error {
+	if field.Type() == reflect.TypeOf(time.Time{}) {
+		layout := time.RFC3339
+		if l, found := options["layout"]; found {
+			layout = l
+		}
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
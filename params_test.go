@@ -0,0 +1,132 @@
+package trixie
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func paramRequest(params map[string]string) *http.Request {
+	return AddRouteParameters(httptest.NewRequest(http.MethodGet, "/", nil), params)
+}
+
+func TestParseUUID(t *testing.T) {
+	if uuid, err := ParseUUID("6BA7B810-9DAD-11D1-80B4-00C04FD430C8"); err != nil || uuid.String() != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
+		t.Errorf("Unexpected uuid (%s, %v)", uuid, err)
+	}
+
+	malformed := []string{
+		"01234567-89ab-cdef-0123-4567-89ab-cd",
+		"6ba7b810-9dad-11d1-80b4-00c04fd430c",
+		"6ba7b810-9dad-11d1-80b4-00c04fd430cx",
+		"6ba7b8109dad-11d1-80b4-00c04fd430c8-",
+		"6ba7b810-9dad-11d1-80b4-00c04fd430c80",
+	}
+
+	for _, s := range malformed {
+		if _, err := ParseUUID(s); err == nil {
+			t.Errorf("Unexpected uuid parsed from %s", s)
+		}
+
+		var uuid UUID
+		if err := uuid.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("Unexpected uuid unmarshaled from %s", s)
+		}
+	}
+}
+
+func TestParamAccessors(t *testing.T) {
+	req := paramRequest(map[string]string{
+		"id":   "42",
+		"uuid": "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"day":  "2017-03-01",
+		"name": "donutloop",
+	})
+
+	if id, err := ParamInt(req, "id"); err != nil || id != 42 {
+		t.Errorf("Unexpected int parameter (%d, %v)", id, err)
+	}
+
+	if uuid, err := ParamUUID(req, "uuid"); err != nil || uuid.String() != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
+		t.Errorf("Unexpected uuid parameter (%s, %v)", uuid, err)
+	}
+
+	if day, err := ParamTime(req, "day", "2006-01-02"); err != nil || day.Month() != time.March {
+		t.Errorf("Unexpected time parameter (%s, %v)", day, err)
+	}
+
+	_, err := ParamInt64(req, "name")
+	if perr, ok := err.(*ParamError); !ok || perr.Name != "name" || perr.Type != "int64" {
+		t.Errorf("Unexpected error (%v)", err)
+	}
+
+	_, err = ParamInt(req, "page")
+	if perr, ok := err.(*ParamError); !ok || perr.Err != ErrParamMissing {
+		t.Errorf("Unexpected error (%v)", err)
+	}
+}
+
+func TestBindParams(t *testing.T) {
+	var params struct {
+		ID      int64     `param:"id"`
+		UUID    UUID      `param:"uuid"`
+		Day     time.Time `param:"day,layout=2006-01-02"`
+		Name    string    `param:"name"`
+		Comment string    `param:"comment,optional"`
+		Ignored string
+	}
+
+	req := paramRequest(map[string]string{
+		"id":   "42",
+		"uuid": "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"day":  "2017-03-01",
+		"name": "donutloop",
+	})
+
+	if err := BindParams(req, &params); err != nil {
+		t.Fatalf("Unexpected error (%s)", err.Error())
+	}
+
+	if params.ID != 42 || params.Name != "donutloop" || params.Day.Day() != 1 || params.UUID.String() != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
+		t.Errorf("Unexpected bound parameters (%+v)", params)
+	}
+
+	req = paramRequest(map[string]string{
+		"id":   "abc",
+		"uuid": "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"day":  "2017-03-01",
+		"name": "donutloop",
+	})
+
+	res := httptest.NewRecorder()
+	if BindParamsOrError(res, req, &params) {
+		t.Fatal("Unexpected successful binding")
+	}
+
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("Unexpected status code (Expected: %d, Actual: %d)", http.StatusBadRequest, res.Code)
+	}
+
+	var body map[string]string
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil || body["parameter"] != "id" || body["type"] != "int64" {
+		t.Errorf("Unexpected error body (%v, %v)", body, err)
+	}
+}
+
+func TestBindParamsUnexportedField(t *testing.T) {
+	var params struct {
+		ID   int64 `param:"id"`
+		page int   `param:"page"`
+	}
+
+	err := BindParams(paramRequest(map[string]string{"id": "42", "page": "2"}), &params)
+	if perr, ok := err.(*ParamError); !ok || perr.Name != "page" || perr.Err != ErrParamUnexported {
+		t.Errorf("Unexpected error (%v)", err)
+	}
+
+	if params.page != 0 {
+		t.Errorf("Unexpected bound unexported field (%d)", params.page)
+	}
+}
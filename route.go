@@ -1,6 +1,10 @@
 package trixie
 
-import "net/http"
+import (
+	"net/http"
+	"regexp"
+	"sort"
+)
 
 type method string
 
@@ -17,6 +21,85 @@ type RouteInterface interface {
 	AddHandlers(Handlers) RouteInterface
 }
 
+// The following interfaces are optional abilities of a route. The router checks
+// for them by type assertion, so a custom route (see Router.UseRoute) only has to
+// implement RouteInterface and can opt in to the abilities it supports.
+
+// HostRoute is a route which can be bound to a host, see Route.SetHost
+type HostRoute interface {
+	SetHost(string) RouteInterface
+	GetHost() string
+}
+
+// ConditionalRoute is a route with conditions besides path and method, see Route.AddMatcher
+type ConditionalRoute interface {
+	AddMatcher(RequestMatcher) RouteInterface
+	GetMatchers() []RequestMatcher
+}
+
+// VersionedRoute is a route which can be bound to an API version, see Route.SetVersion
+type VersionedRoute interface {
+	SetVersion(string) RouteInterface
+	GetVersion() string
+}
+
+// MediaTypeRoute is a route which declares the media types it consumes and produces, see Route.Consumes
+type MediaTypeRoute interface {
+	Consumes(...string) RouteInterface
+	GetConsumes() []string
+	Produces(...string) RouteInterface
+	GetProduces() []string
+}
+
+// registrable is a route which is told by the router that it's registered, see Route.SetHost
+type registrable interface {
+	markRegistered()
+}
+
+func (r *Route) markRegistered() {
+	r.registered = true
+}
+
+// hostOf returns the host of the route, it's empty if the route isn't a HostRoute
+func hostOf(route RouteInterface) string {
+	if hr, ok := route.(HostRoute); ok {
+		return hr.GetHost()
+	}
+	return ""
+}
+
+// matchersOf returns the conditions of the route, it's nil if the route isn't a ConditionalRoute
+func matchersOf(route RouteInterface) []RequestMatcher {
+	if cr, ok := route.(ConditionalRoute); ok {
+		return cr.GetMatchers()
+	}
+	return nil
+}
+
+// versionOf returns the version of the route, it's empty if the route isn't a VersionedRoute
+func versionOf(route RouteInterface) string {
+	if vr, ok := route.(VersionedRoute); ok {
+		return vr.GetVersion()
+	}
+	return ""
+}
+
+// consumesOf returns the media types the route consumes, it's nil if the route isn't a MediaTypeRoute
+func consumesOf(route RouteInterface) []string {
+	if mr, ok := route.(MediaTypeRoute); ok {
+		return mr.GetConsumes()
+	}
+	return nil
+}
+
+// producesOf returns the media types the route produces, it's nil if the route isn't a MediaTypeRoute
+func producesOf(route RouteInterface) []string {
+	if mr, ok := route.(MediaTypeRoute); ok {
+		return mr.GetProduces()
+	}
+	return nil
+}
+
 func NewRoute() RouteInterface {
 	return &Route{
 		handlers: Handlers{},
@@ -25,16 +108,27 @@ func NewRoute() RouteInterface {
 
 type Route struct {
 	handlers Handlers
+	// registered is set by the router, the host can't be changed afterwards
+	registered bool
+	// revision counts the changes of the handlers, see chains
+	revision uint64
 	pattern  string
+	host     string
+	matchers []RequestMatcher
+	version  string
+	consumes []string
+	produces []string
 }
 
 func (r *Route) AddHandlerFunc(method string, handler func(http.ResponseWriter, *http.Request)) RouteInterface {
 	r.handlers[method] = http.HandlerFunc(handler)
+	r.revision++
 	return r
 }
 
 func (r *Route) AddHandler(method string, handler http.Handler) RouteInterface {
 	r.handlers[method] = handler
+	r.revision++
 	return r
 }
 
@@ -47,6 +141,7 @@ func (r *Route) AddHandlers(handlers Handlers) RouteInterface {
 	for method, handler := range handlers {
 		r.handlers[method] = handler
 	}
+	r.revision++
 
 	return r
 }
@@ -55,6 +150,10 @@ func (r *Route) GetHandlers() Handlers {
 	return r.handlers
 }
 
+func (r *Route) handlersRevision() uint64 {
+	return r.revision
+}
+
 func (r *Route) SetPattern(pattern string) RouteInterface {
 	r.pattern = pattern
 	return r
@@ -64,9 +163,184 @@ func (r *Route) GetPattern() string {
 	return r.pattern
 }
 
+// SetHost binds the route to a host, the route only matches requests for this host.
+// The host has to be set before the route is registered, e.g. in the callback of Router.Path,
+// SetHost panics with ErrHostAfterRegistration otherwise.
+func (r *Route) SetHost(host string) RouteInterface {
+	if r.registered && host != r.host {
+		panic(ErrHostAfterRegistration.Error())
+	}
+	r.host = host
+	return r
+}
+
+func (r *Route) GetHost() string {
+	return r.host
+}
+
 func (r *Route) HasHandler(method string) bool {
 	if _, found := r.handlers[method]; found {
 		return true
 	}
 	return false
 }
+
+// MatchHeader adds the condition that the request has the header with the value,
+// an empty value only requires the header.
+func (r *Route) MatchHeader(key, value string) RouteInterface {
+	return r.AddMatcher(headerMatcher{key: http.CanonicalHeaderKey(key), value: value})
+}
+
+// MatchHeaderRegexp adds the condition that a value of the header matches the expression.
+// It panics if the expression is invalid.
+func (r *Route) MatchHeaderRegexp(key, expr string) RouteInterface {
+	return r.AddMatcher(headerRegexpMatcher{key: http.CanonicalHeaderKey(key), regexp: regexp.MustCompile(expr)})
+}
+
+// MatchQuery adds the condition that the request has the query parameter with the value,
+// an empty value only requires the parameter.
+func (r *Route) MatchQuery(key, value string) RouteInterface {
+	return r.AddMatcher(queryMatcher{key: key, value: value})
+}
+
+// MatchScheme adds the condition that the request uses one of the schemes (e.g. https).
+func (r *Route) MatchScheme(schemes ...string) RouteInterface {
+	return r.AddMatcher(schemeMatcher(schemes))
+}
+
+// MatchContentType adds the condition that the body has one of the media types (e.g. application/json).
+func (r *Route) MatchContentType(contentTypes ...string) RouteInterface {
+	return r.AddMatcher(contentTypeMatcher(contentTypes))
+}
+
+// AddMatcher adds a condition the request has to fulfil to match the route.
+func (r *Route) AddMatcher(matcher RequestMatcher) RouteInterface {
+	r.matchers = append(r.matchers, matcher)
+	return r
+}
+
+func (r *Route) GetMatchers() []RequestMatcher {
+	return r.matchers
+}
+
+// SetVersion binds the route to an API version, which is negotiated by the Accept
+// header of the request, e.g. application/vnd.acme.v2+json or application/json; version=2.
+func (r *Route) SetVersion(version string) RouteInterface {
+	r.version = version
+	return r
+}
+
+func (r *Route) GetVersion() string {
+	return r.version
+}
+
+// Consumes declares the media types of the request body, e.g. application/json.
+// The router answers 415 Unsupported Media Type for requests with another Content-Type.
+func (r *Route) Consumes(mediaTypes ...string) RouteInterface {
+	r.consumes = append(r.consumes, mediaTypes...)
+	return r
+}
+
+func (r *Route) GetConsumes() []string {
+	return r.consumes
+}
+
+// Produces declares the media types of the response, e.g. application/json.
+// The router answers 406 Not Acceptable for requests which accept none of them.
+func (r *Route) Produces(mediaTypes ...string) RouteInterface {
+	r.produces = append(r.produces, mediaTypes...)
+	return r
+}
+
+func (r *Route) GetProduces() []string {
+	return r.produces
+}
+
+// routeGroup holds the routes which share a pattern, e.g. a route for GET and
+// one for POST or two routes for GET with different conditions.
+// It's the leaf of a tree node if more than one route is inserted at the node.
+type routeGroup struct {
+	routes []RouteInterface
+}
+
+func (g *routeGroup) AddHandlerFunc(method string, handler func(http.ResponseWriter, *http.Request)) RouteInterface {
+	g.routes[0].AddHandlerFunc(method, handler)
+	return g
+}
+
+func (g *routeGroup) AddHandler(method string, handler http.Handler) RouteInterface {
+	g.routes[0].AddHandler(method, handler)
+	return g
+}
+
+// GetHandler returns the handler of the first route which has one for the method
+func (g *routeGroup) GetHandler(method string) http.Handler {
+	for _, route := range g.routes {
+		if route.HasHandler(method) {
+			return route.GetHandler(method)
+		}
+	}
+	return nil
+}
+
+func (g *routeGroup) AddHandlers(handlers Handlers) RouteInterface {
+	g.routes[0].AddHandlers(handlers)
+	return g
+}
+
+// GetHandlers returns the handlers of all routes, the first route wins for a method
+func (g *routeGroup) GetHandlers() Handlers {
+	handlers := Handlers{}
+	for i := len(g.routes) - 1; i >= 0; i-- {
+		for method, handler := range g.routes[i].GetHandlers() {
+			handlers[method] = handler
+		}
+	}
+	return handlers
+}
+
+func (g *routeGroup) SetPattern(pattern string) RouteInterface {
+	for _, route := range g.routes {
+		route.SetPattern(pattern)
+	}
+	return g
+}
+
+func (g *routeGroup) GetPattern() string {
+	return g.routes[0].GetPattern()
+}
+
+func (g *routeGroup) HasHandler(method string) bool {
+	for _, route := range g.routes {
+		if route.HasHandler(method) {
+			return true
+		}
+	}
+	return false
+}
+
+// candidates returns the routes of a leaf, routes with more conditions come first.
+func candidates(leaf RouteInterface) []RouteInterface {
+	g, ok := leaf.(*routeGroup)
+	if !ok {
+		return []RouteInterface{leaf}
+	}
+
+	routes := make([]RouteInterface, len(g.routes))
+	copy(routes, g.routes)
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(matchersOf(routes[i])) > len(matchersOf(routes[j]))
+	})
+
+	return routes
+}
+
+// matches reports whether the request fulfils all conditions of the route
+func matches(route RouteInterface, req *http.Request) bool {
+	for _, matcher := range matchersOf(route) {
+		if !matcher.Match(req) {
+			return false
+		}
+	}
+	return true
+}
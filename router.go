@@ -1,6 +1,7 @@
 package trixie
 
 import (
+	"context"
 	"github.com/donutloop/trixie/middleware"
 	"net/http"
 	"path"
@@ -27,6 +28,17 @@ func NewRouter() *Router {
 type Router struct {
 	// Configurable Handler to be used when no route matches.
 	NotFoundHandler http.Handler
+	// Configurable Handler to be used when a route matches the path but not the method.
+	// The NotFoundHandler is used if it's nil.
+	MethodNotAllowedHandler http.Handler
+	// Configurable Handler to be used when routes match path and method but neither the
+	// version nor a media type the request accepts. It answers 406 Not Acceptable if it's nil.
+	NotAcceptableHandler http.Handler
+	// Configurable Handler to be used when routes match path and method but don't consume
+	// the media type of the request body. It answers 415 Unsupported Media Type if it's nil.
+	UnsupportedMediaTypeHandler http.Handler
+	// This defines the version of the API for requests which don't ask for a version.
+	DefaultVersion string
 
 	// This defines the flag for new routes.
 	StrictSlash bool
@@ -36,20 +48,47 @@ type Router struct {
 	UseEncodedPath bool
 	// This defines a flag for all routes.
 	CaseSensitiveURL bool
+	// This defines whether the port of a request has to match the port of a host pattern.
+	// Ports are ignored if it's false, a host pattern without port only matches
+	// requests without port if it's true.
+	MatchHostPort bool
 	// this builds a tree
 	treeConstructor func() RouteTreeInterface
 	// This defines the tree for routes.
 	tree RouteTreeInterface
+	// This defines the trees for routes bound to a host.
+	hostTrees []*hostTree
+	// This defines the types of param segments (e.g. :number)
+	segmentTypes *SegmentTypes
 	// this builds a route
 	routeConstructor func() RouteInterface
 
-	// The middleware stack
+	// The middleware stack, it runs after a route matched
 	middlewares []middleware.Middleware
+	// The handlers of the routes composed with the middleware stack
+	chains chains
+	// The pre-routing middleware stack, it wraps the whole dispatch
+	preMiddlewares []middleware.Middleware
+	// The dispatch composed with the pre-routing middleware stack
+	preHandler http.Handler
 }
 
 // Use appends a middleware handler to the mux middleware stack.
+// It runs after a route matched, right before the handler of the route.
+// The stack is composed with the handlers of the routes once, not per request.
 func (r *Router) Use(middlewares ...middleware.Middleware) {
 	r.middlewares = append(r.middlewares, middlewares...)
+	r.chains.recompose(r.middlewares)
+}
+
+// UsePreRouting appends a middleware handler to the pre-routing stack.
+// It runs before the lookup for every request, also for requests which end in
+// the not found or method not allowed handler, so it can rewrite the path,
+// override the method, authenticate or log all requests.
+// The stack is composed with the dispatch once, not per request.
+func (r *Router) UsePreRouting(middlewares ...middleware.Middleware) {
+	r.preMiddlewares = append(r.preMiddlewares, middlewares...)
+	r.preHandler = middleware.Stack(r.preMiddlewares...).ThenFunc(r.dispatch)
 }
 
 // UseRoute that you can use different route versions
@@ -58,20 +97,62 @@ func (r *Router) UseRoute(constructor func() RouteInterface) {
 	r.routeConstructor = constructor
 }
 
+// RegisterSegmentType adds a type for param segments, which can be used
+// in patterns like /user/:uuid or /user/{id:uuid}.
+// Types have to be registered before the routes which use them.
+// See SegmentTypes for the built-in types (segment.go)
+func (r *Router) RegisterSegmentType(name string, matcher SegmentMatcher) {
+	r.getSegmentTypes().Register(name, matcher)
+}
+
+func (r *Router) getSegmentTypes() *SegmentTypes {
+	if r.segmentTypes == nil {
+		r.segmentTypes = defaultSegmentTypes.clone()
+	}
+	return r.segmentTypes
+}
+
+// newTree builds a tree which uses the segment types of the router
+func (r *Router) newTree() RouteTreeInterface {
+	tree := r.treeConstructor()
+	if st, ok := tree.(SegmentTypesTree); ok {
+		st.UseSegmentTypes(r.getSegmentTypes())
+	}
+	return tree
+}
+
 // UseTree that you can use different tree versions
 // See TreeInterface for more details (tree.go)
 func (r *Router) UseTree(constructor func() RouteTreeInterface) {
 	r.treeConstructor = constructor
 }
 
-// ServeHTTP dispatches the handler registered in the matched route.
+// ServeHTTP runs the pre-routing middleware stack (see UsePreRouting) and
+// dispatches the handler registered in the matched route.
 //
 // When there is a match, the route variables can be retrieved calling
-// trixie.GetRouteParameters(req)
+// trixie.GetRouteParameters(req) or req.PathValue(name) and
+// the pattern of the matched route is stored in req.Pattern
+//
+// When there is no match, the not found handler can retrieve the
+// closest registered routes calling trixie.GetSuggestions(req)
 //
 // and the route queries can be retrieved calling
 // middleware.GetQueries(req).Get("content-type") or middleware.GetQueries(req).GetAll()
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.preHandler == nil {
+		r.dispatch(w, req)
+		return
+	}
+
+	// the match is filled in by dispatch, so pre-routing middleware can read it
+	req = req.WithContext(context.WithValue(req.Context(), middleware.MatchKey, &middleware.Match{}))
+	r.preHandler.ServeHTTP(w, req)
+}
+
+// dispatch looks up the request and serves it with the handler of the matched route
+// or the not found or method not allowed handler.
+func (r *Router) dispatch(w http.ResponseWriter, req *http.Request) {
 
 	found := Methods.lookup(req.Method)
 	if !found {
@@ -99,16 +180,40 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		req.URL.Path = strings.ToLower(req.URL.Path)
 	}
 
-	route, params, err := r.tree.Find(r.tree.GetRoot(), req.URL.Path)
-	if err != nil || !route.HasHandler(req.Method) {
+	match, err := r.Match(req)
+	if err != nil {
+		req = addSuggester(req, r, r.requestPath(req))
+	}
+
+	// the response depends on the version the request accepts
+	if match.varyAccept {
+		w.Header().Add("Vary", "Accept")
+	}
+
+	if err == ErrMethodNotAllowed {
+		r.methodNotAllowedHandler().ServeHTTP(w, req)
+		return
+	} else if err == ErrNotAcceptable {
+		r.notAcceptableHandler().ServeHTTP(w, req)
+		return
+	} else if err == ErrUnsupportedMediaType {
+		r.unsupportedMediaTypeHandler().ServeHTTP(w, req)
+		return
+	} else if err != nil {
 		r.notFoundHandler().ServeHTTP(w, req)
 		return
 	}
 
-	req = AddCurrentRoute(req, route)
-	req = AddRouteParameters(req, params)
+	if m := middleware.GetMatch(req); m != nil {
+		m.Pattern, m.Params = match.Pattern, match.Params
+	}
+
+	req = AddCurrentRoute(req, match.Route)
+	req = AddRouteParameters(req, match.Params)
+	req = addPathValues(req, match)
+	req = addRouteValues(req, r.getSegmentTypes(), match)
 
-	middleware.Stack(r.middlewares...).Then(route.GetHandler(req.Method)).ServeHTTP(w, req)
+	r.chains.handler(match.Route, req.Method, r.middlewares).ServeHTTP(w, req)
 }
 
 func (r *Router) notFoundHandler() http.Handler {
@@ -119,6 +224,34 @@ func (r *Router) notFoundHandler() http.Handler {
 	return r.NotFoundHandler
 }
 
+func (r *Router) methodNotAllowedHandler() http.Handler {
+	if r.MethodNotAllowedHandler == nil {
+		return r.notFoundHandler()
+	}
+
+	return r.MethodNotAllowedHandler
+}
+
+func (r *Router) notAcceptableHandler() http.Handler {
+	if r.NotAcceptableHandler == nil {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		})
+	}
+
+	return r.NotAcceptableHandler
+}
+
+func (r *Router) unsupportedMediaTypeHandler() http.Handler {
+	if r.UnsupportedMediaTypeHandler == nil {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+		})
+	}
+
+	return r.UnsupportedMediaTypeHandler
+}
+
 // cleanPath returns the canonical path for p, eliminating . and .. elements.
 // Borrowed from the net/http package.
 // /net/http/server.go
@@ -142,21 +275,75 @@ func cleanPath(p string) string {
 // RegisterRoute registers and validates the given route
 func (r *Router) RegisterRoute(route RouteInterface) {
 
+	r.chains.compose(route, r.middlewares)
+
+	// the host decides the tree of the route, so it's fixed from now on
+	if rr, ok := route.(registrable); ok {
+		rr.markRegistered()
+	}
+
+	if hostOf(route) != "" {
+		r.hostTree(hostOf(route)).Insert(route)
+		return
+	}
+
 	if r.tree == nil {
-		r.tree = r.treeConstructor()
+		r.tree = r.newTree()
 	}
 
 	r.tree.Insert(route)
 }
 
+// hostTree returns the tree for routes bound to the host, it's created if it doesn't exist.
+func (r *Router) hostTree(host string) RouteTreeInterface {
+	for _, ht := range r.hostTrees {
+		if ht.host == host {
+			return ht.tree
+		}
+	}
+
+	ht := newHostTree(host, r.newTree(), r.getSegmentTypes())
+
+	// the most specific host is tried first
+	i := len(r.hostTrees)
+	for i > 0 && ht.specificity() > r.hostTrees[i-1].specificity() {
+		i--
+	}
+
+	r.hostTrees = append(r.hostTrees, nil)
+	copy(r.hostTrees[i+1:], r.hostTrees[i:])
+	r.hostTrees[i] = ht
+
+	return ht.tree
+}
+
 func (r *Router) ValidateRoute(route RouteInterface) {
 	for _, validator := range Validatoren {
+		// the defaults of optional params are checked against the segment types of the router
+		if _, ok := validator.(segmentValidator); ok {
+			validator = segmentValidator{segmentTypes: r.getSegmentTypes()}
+		}
+
 		err := validator.Validate(route)
 
 		if err != nil {
 			panic(err.Error())
 		}
 	}
+
+	segments := strings.Split(strings.Trim(fullPattern(route.GetPattern()), "/"), "/")
+	if hostOf(route) != "" {
+		host, _ := splitHostPort(hostOf(route))
+		segments = append(segments, strings.Split(host, ".")...)
+	}
+
+	for _, seg := range segments {
+		for _, kind := range segmentKinds(seg) {
+			if _, found := r.getSegmentTypes().Lookup(kind); !found {
+				panic(NewBadPathError("Segment type " + kind + " is not registered").Error())
+			}
+		}
+	}
 }
 
 // Handle registers a new route with a matcher for the URL path.
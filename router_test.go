@@ -3,6 +3,7 @@ package trixie
 import (
 	"bytes"
 	"fmt"
+	"github.com/donutloop/trixie/middleware"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -96,15 +97,17 @@ func testSingleRoute(rt routeTestCase) (int, string) {
 	return res.Code, content.String()
 }
 
+// namedHandler returns a handler which answers with its name,
+// so a test can tell which of the routes served a request.
+func namedHandler(name string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(name))
+	}
+}
+
 func TestRouterWithMultiRoutes(t *testing.T) {
 	router := Classic()
 
-	handler := func(key string) func(w http.ResponseWriter, r *http.Request) {
-		return func(w http.ResponseWriter, r *http.Request) {
-			w.Write([]byte(key))
-		}
-	}
-
 	paths := map[string]struct {
 		key  string
 		path string
@@ -148,7 +151,7 @@ func TestRouterWithMultiRoutes(t *testing.T) {
 	}
 
 	for path, pathInfo := range paths {
-		router.Get(path, handler(pathInfo.key))
+		router.Get(path, namedHandler(pathInfo.key))
 	}
 
 	server := httptest.NewServer(router)
@@ -177,3 +180,230 @@ func TestRouterWithMultiRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestRouterPathValue(t *testing.T) {
+	router := Classic()
+
+	var value, pattern string
+	router.Get("/api/user/:number", func(w http.ResponseWriter, r *http.Request) {
+		value = r.PathValue("seg2")
+		pattern = r.Pattern
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/user/7", nil))
+
+	if value != "7" {
+		t.Errorf("Unexpected path value (Expected: 7, Actual: %s)", value)
+	}
+
+	if pattern != "/api/user/:number" {
+		t.Errorf("Unexpected pattern (Expected: /api/user/:number, Actual: %s)", pattern)
+	}
+}
+
+func TestRouterPreRoutingMiddleware(t *testing.T) {
+	router := Classic()
+
+	var logged []string
+	router.UsePreRouting(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			logged = append(logged, r.Method+" "+r.URL.Path+" "+middleware.GetMatch(r).Pattern+" "+GetRouteParameters(r)["id"])
+		})
+	})
+
+	// method override before the lookup
+	router.UsePreRouting(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if method := r.Header.Get("X-HTTP-Method-Override"); method != "" {
+				r.Method = method
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	var order []string
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "post-match")
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	router.Delete("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/items/1", nil)
+	req.Header.Set("X-HTTP-Method-Override", http.MethodDelete)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || len(order) != 2 || order[0] != "post-match" || order[1] != "handler" {
+		t.Errorf("Unexpected dispatch (Status: %d, Order: %v)", rec.Code, order)
+	}
+
+	order = nil
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	if rec.Code != http.StatusNotFound || len(order) != 0 {
+		t.Errorf("Unexpected dispatch of a miss (Status: %d, Order: %v)", rec.Code, order)
+	}
+
+	expected := []string{"DELETE /items/1 /items/{id} 1", "GET /missing  "}
+	if len(logged) != len(expected) || logged[0] != expected[0] || logged[1] != expected[1] {
+		t.Errorf("Unexpected logged requests (Expected: %v, Actual: %v)", expected, logged)
+	}
+}
+
+func TestRouterComposesMiddlewareOnce(t *testing.T) {
+	router := Classic()
+
+	constructed := 0
+	counter := func(next http.Handler) http.Handler {
+		constructed++
+		return next
+	}
+
+	router.Use(counter)
+	router.Get("/a", func(w http.ResponseWriter, r *http.Request) {})
+	router.Get("/b", func(w http.ResponseWriter, r *http.Request) {}).AddHandlerFunc(http.MethodPost, func(w http.ResponseWriter, r *http.Request) {})
+
+	for i := 0; i < 3; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/b", nil))
+	}
+
+	// /a GET at registration, /b POST on demand
+	if constructed != 3 {
+		t.Errorf("Unexpected count of constructor calls (Expected: 3, Actual: %d)", constructed)
+	}
+
+	preConstructed := 0
+	router.UsePreRouting(func(next http.Handler) http.Handler {
+		preConstructed++
+		return next
+	})
+
+	for i := 0; i < 5; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	}
+
+	if preConstructed != 1 {
+		t.Errorf("Unexpected count of pre-routing constructor calls (Expected: 1, Actual: %d)", preConstructed)
+	}
+
+	var called bool
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	if !called {
+		t.Errorf("Unexpected miss of middleware added after registration")
+	}
+}
+
+func TestRouterReplacedHandler(t *testing.T) {
+	router := Classic()
+
+	served := func() string {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/b", nil))
+		return rec.Body.String()
+	}
+
+	route := router.Get("/b", namedHandler("b1"))
+	if name := served(); name != "b1" {
+		t.Errorf("Unexpected handler (Expected: b1, Actual: %s)", name)
+	}
+
+	route.AddHandlerFunc(http.MethodGet, namedHandler("b2"))
+	if name := served(); name != "b2" {
+		t.Errorf("Unexpected handler after replacing it (Expected: b2, Actual: %s)", name)
+	}
+
+	route.AddHandlers(Handlers{http.MethodGet: http.HandlerFunc(namedHandler("b3"))})
+	if name := served(); name != "b3" {
+		t.Errorf("Unexpected handler after replacing it (Expected: b3, Actual: %s)", name)
+	}
+}
+
+// coreRoute and coreTree only implement the core interfaces, like custom
+// routes and trees written before the optional interfaces existed.
+type coreRoute struct {
+	handlers Handlers
+	pattern  string
+}
+
+func (r *coreRoute) AddHandler(method string, handler http.Handler) RouteInterface {
+	r.handlers[method] = handler
+	return r
+}
+
+func (r *coreRoute) AddHandlerFunc(method string, handler func(http.ResponseWriter, *http.Request)) RouteInterface {
+	return r.AddHandler(method, http.HandlerFunc(handler))
+}
+
+func (r *coreRoute) SetPattern(pattern string) RouteInterface {
+	r.pattern = pattern
+	return r
+}
+
+func (r *coreRoute) GetPattern() string                    { return r.pattern }
+func (r *coreRoute) GetHandler(method string) http.Handler { return r.handlers[method] }
+func (r *coreRoute) GetHandlers() Handlers                 { return r.handlers }
+
+func (r *coreRoute) HasHandler(method string) bool {
+	_, found := r.handlers[method]
+	return found
+}
+
+func (r *coreRoute) AddHandlers(handlers Handlers) RouteInterface {
+	for method, handler := range handlers {
+		r.handlers[method] = handler
+	}
+	return r
+}
+
+type coreTree struct {
+	RouteTreeInterface
+}
+
+func TestRouterWithCoreRouteAndTree(t *testing.T) {
+	router := NewRouter()
+	router.UseTree(func() RouteTreeInterface { return coreTree{NewTree(NewNode)()} })
+	router.UseRoute(func() RouteInterface { return &coreRoute{handlers: Handlers{}} })
+
+	var served bool
+	router.Get("/users/:number", func(w http.ResponseWriter, r *http.Request) {
+		served = true
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if !served || rec.Code != http.StatusOK {
+		t.Errorf("Unexpected response (Served: %v, Status: %d)", served, rec.Code)
+	}
+
+	if trace := router.Explain(http.MethodGet, "/users/1"); trace.Err != nil || trace.Route == nil {
+		t.Errorf("Unexpected trace (Error: %v, Route: %v)", trace.Err, trace.Route)
+	}
+
+	if suggestions := router.Suggest(http.MethodGet, "/user/1"); len(suggestions) != 0 {
+		t.Errorf("Unexpected suggestions of a tree without routes (%v)", suggestions)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected a panic for a host pattern of a route without host")
+			}
+		}()
+		router.HandlePatternFunc("GET example.com/items", func(w http.ResponseWriter, r *http.Request) {})
+	}()
+}
@@ -0,0 +1,286 @@
+package trixie
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SegmentMatcher matches the path segments of a parameter type like :number.
+//
+// Param nodes are tried in order of the priority of their matchers,
+// the matcher with the highest priority first. Regex and static nodes
+// are always tried before param nodes.
+type SegmentMatcher interface {
+	// Match reports whether the path segment is a valid value
+	Match(seg string) bool
+	// Priority of the matcher
+	Priority() int
+}
+
+// SegmentConverter is implemented by segment matchers which convert
+// the value of a segment, e.g. into an int64.
+// The converted values can be retrieved calling trixie.GetRouteValues(req)
+type SegmentConverter interface {
+	Convert(seg string) (interface{}, error)
+}
+
+// SegmentType is a segment matcher built of functions.
+type SegmentType struct {
+	// Validate reports whether the path segment is a valid value
+	Validate func(string) bool
+	// Converter converts a valid value, it's optional
+	Converter func(string) (interface{}, error)
+	// Rank is the priority of the type
+	Rank int
+}
+
+func (st SegmentType) Match(seg string) bool { return st.Validate(seg) }
+
+func (st SegmentType) Priority() int { return st.Rank }
+
+// Convert converts the value of a segment, it returns the segment itself without converter.
+func (st SegmentType) Convert(seg string) (interface{}, error) {
+	if st.Converter == nil {
+		return seg, nil
+	}
+	return st.Converter(seg)
+}
+
+// RegexSegmentType returns a segment type which matches the whole segment against expr.
+func RegexSegmentType(expr string, priority int) SegmentType {
+	re := regexp.MustCompile("^(?:" + expr + ")$")
+	return SegmentType{Validate: re.MatchString, Rank: priority}
+}
+
+// SegmentTypes is a registry of segment types by name.
+type SegmentTypes struct {
+	types map[string]SegmentMatcher
+}
+
+// NewSegmentTypes returns a registry with the built-in types
+//
+//	string, number, uuid, slug, date, int64, hex
+//
+// and in, which is used with the allowed values, e.g. {lang:in(en,de,fr)}
+func NewSegmentTypes() *SegmentTypes {
+	st := &SegmentTypes{types: map[string]SegmentMatcher{}}
+
+	st.Register("string", RegexSegmentType("[a-zA-Z]+", 10))
+	st.Register("number", RegexSegmentType("[0-9]+", 30))
+	st.Register("slug", RegexSegmentType("[a-z0-9]+(?:-[a-z0-9]+)*", 15))
+	st.Register("hex", RegexSegmentType("[0-9a-fA-F]+", 20))
+	st.Register("int64", SegmentType{
+		Validate:  func(seg string) bool { _, err := strconv.ParseInt(seg, 10, 64); return err == nil },
+		Converter: func(seg string) (interface{}, error) { return strconv.ParseInt(seg, 10, 64) },
+		Rank:      40,
+	})
+	st.Register("date", SegmentType{
+		Validate:  func(seg string) bool { _, err := time.Parse("2006-01-02", seg); return err == nil },
+		Converter: func(seg string) (interface{}, error) { return time.Parse("2006-01-02", seg) },
+		Rank:      50,
+	})
+	st.Register("uuid", SegmentType{
+		Validate:  func(seg string) bool { _, err := ParseUUID(seg); return err == nil },
+		Converter: func(seg string) (interface{}, error) { return ParseUUID(seg) },
+		Rank:      60,
+	})
+	st.Register("in", SegmentType{Validate: anySegment.Validate, Rank: 70})
+
+	return st
+}
+
+// Register adds or replaces the segment type with the given name
+func (st *SegmentTypes) Register(name string, matcher SegmentMatcher) {
+	st.types[name] = matcher
+}
+
+// Lookup returns the segment type with the given name
+func (st *SegmentTypes) Lookup(name string) (SegmentMatcher, bool) {
+	matcher, found := st.types[name]
+	return matcher, found
+}
+
+// clone returns a copy of the registry
+func (st *SegmentTypes) clone() *SegmentTypes {
+	c := &SegmentTypes{types: make(map[string]SegmentMatcher, len(st.types))}
+	for name, matcher := range st.types {
+		c.types[name] = matcher
+	}
+	return c
+}
+
+// segmentKinds returns the names of the segment types used in a segment
+func segmentKinds(seg string) []string {
+	typ, _, kind := parseSegment(seg)
+
+	switch typ {
+	case paramNode:
+		if kind != "" {
+			name, _, _ := splitKind(kind)
+			return []string{name}
+		}
+	case mixedNode:
+		parts, _ := parseMixedSegment(seg)
+		kinds := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if part.kind != "" {
+				name, _, _ := splitKind(part.kind)
+				kinds = append(kinds, name)
+			}
+		}
+		return kinds
+	}
+
+	return nil
+}
+
+// matcher returns the matcher of a segment type and its constraint,
+// a param without type matches every non empty segment.
+func (st *SegmentTypes) matcher(kind string) SegmentMatcher {
+	if kind == "" {
+		return anySegment
+	}
+
+	name, args, constrained := splitKind(kind)
+	matcher, found := st.Lookup(name)
+	if !found || !constrained {
+		return matcher
+	}
+
+	// an invalid constraint never matches, it's rejected by the segment validator
+	checks, err := parseConstraint(name, args)
+	if err != nil {
+		return nil
+	}
+
+	return constrainedSegment{SegmentMatcher: matcher, checks: checks}
+}
+
+// defaultSegmentTypes is used by trees without own registry
+var defaultSegmentTypes = NewSegmentTypes()
+
+// anySegment matches every non empty segment
+var anySegment = SegmentType{Validate: func(seg string) bool { return seg != "" }}
+
+// parseSegment returns the node type, the parameter name and the
+// segment type of a pattern segment:
+//
+//	:number        param node, unnamed, type number
+//	{id}           param node, named id, no type
+//	{id:uuid}      param node, named id, type uuid
+//	{id:int64(1..)} param node, named id, type int64 with constraint
+//	{path...}      catch-all node, named path
+//	{name}.{ext}   mixed node, see parseMixedSegment
+//	#([0-9]{1,})   regex node
+func parseSegment(seg string) (typ nodeType, name, kind string) {
+	switch {
+	case strings.HasPrefix(seg, "#"):
+		return regexNode, "", ""
+	case strings.HasPrefix(seg, ":") && len(seg) > 1:
+		return paramNode, "", seg[1:]
+	case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") && strings.Count(seg, "{") == 1:
+		inner := seg[1 : len(seg)-1]
+		if strings.HasSuffix(inner, "...") {
+			return catchAllNode, strings.TrimSuffix(inner, "..."), ""
+		}
+		if i := strings.Index(inner, ":"); i >= 0 {
+			return paramNode, inner[:i], inner[i+1:]
+		}
+		return paramNode, inner, ""
+	case strings.Contains(seg, "{"):
+		return mixedNode, "", ""
+	}
+	return staticNode, "", ""
+}
+
+// segmentPart is a literal or a capture of a mixed segment
+type segmentPart struct {
+	capture bool
+	literal string
+	name    string
+	kind    string
+	matcher SegmentMatcher
+}
+
+// parseMixedSegment splits a segment which combines literals with captures
+// like {name}.{ext}, v{version:number} or {id}-{size}.png into its parts.
+// Two captures have to be separated by a literal.
+func parseMixedSegment(seg string) ([]segmentPart, error) {
+	parts := make([]segmentPart, 0)
+	rest := seg
+
+	for rest != "" {
+		open := strings.Index(rest, "{")
+		if open < 0 {
+			parts = append(parts, segmentPart{literal: rest})
+			break
+		}
+
+		if open > 0 {
+			parts = append(parts, segmentPart{literal: rest[:open]})
+		}
+
+		end := strings.Index(rest, "}")
+		if end < open || strings.Contains(rest[open+1:end], "{") {
+			return nil, NewBadPathError("Segment " + seg + " has unbalanced braces")
+		}
+
+		if len(parts) > 0 && parts[len(parts)-1].capture {
+			return nil, NewBadPathError("Segment " + seg + " has captures without literal in between")
+		}
+
+		part := segmentPart{capture: true, name: rest[open+1 : end]}
+		if i := strings.Index(part.name, ":"); i >= 0 {
+			part.name, part.kind = part.name[:i], part.name[i+1:]
+		}
+		parts = append(parts, part)
+
+		rest = rest[end+1:]
+	}
+
+	return parts, nil
+}
+
+// matchParts matches s against the parts of a mixed segment and stores the
+// captured values in captures, if it's not nil.
+//
+// Captures are matched from left to right, each one is greedy and takes as much
+// of s as possible, as long as the value is valid for its type and the rest of s
+// matches the remaining parts, e.g. {name}.{ext} captures "archive.tar" and "gz"
+// of "archive.tar.gz".
+func matchParts(parts []segmentPart, s string, captures []string) bool {
+	if len(parts) == 0 {
+		return s == ""
+	}
+
+	part := parts[0]
+	if !part.capture {
+		return strings.HasPrefix(s, part.literal) && matchParts(parts[1:], s[len(part.literal):], captures)
+	}
+
+	for end := len(s); end > 0; end-- {
+		if len(parts) > 1 && !strings.HasPrefix(s[end:], parts[1].literal) {
+			continue
+		}
+
+		if part.matcher == nil || !part.matcher.Match(s[:end]) {
+			continue
+		}
+
+		var next []string
+		if captures != nil {
+			next = captures[1:]
+		}
+
+		if matchParts(parts[1:], s[end:], next) {
+			if captures != nil {
+				captures[0] = s[:end]
+			}
+			return true
+		}
+	}
+
+	return false
+}
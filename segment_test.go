@@ -0,0 +1,179 @@
+package trixie
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSegmentTypes(t *testing.T) {
+	segmentTypes := NewSegmentTypes()
+
+	testCases := []struct {
+		kind  string
+		valid []string
+		bad   []string
+	}{
+		{kind: "string", valid: []string{"golang", "Go"}, bad: []string{"abc1", "1abc", "go-lang"}},
+		{kind: "number", valid: []string{"42", "007"}, bad: []string{"abc1", "1abc", "4-2"}},
+		{kind: "uuid", valid: []string{"6ba7b810-9dad-11d1-80b4-00c04fd430c8"}, bad: []string{"6ba7b810", "golang", "01234567-89ab-cdef-0123-4567-89ab-cd"}},
+		{kind: "slug", valid: []string{"hello-world", "go2"}, bad: []string{"Hello", "hello--world", "-go"}},
+		{kind: "date", valid: []string{"2017-03-01"}, bad: []string{"2017-13-01", "today"}},
+		{kind: "int64", valid: []string{"42", "-7"}, bad: []string{"4a", "99999999999999999999"}},
+		{kind: "hex", valid: []string{"deadBEEF", "42"}, bad: []string{"xyz"}},
+	}
+
+	for _, testCase := range testCases {
+		matcher, found := segmentTypes.Lookup(testCase.kind)
+		if !found {
+			t.Errorf("Unexpected missing segment type %s", testCase.kind)
+			continue
+		}
+
+		for _, seg := range testCase.valid {
+			if !matcher.Match(seg) {
+				t.Errorf("Unexpected mismatch of %s (Type: %s)", seg, testCase.kind)
+			}
+		}
+
+		for _, seg := range testCase.bad {
+			if matcher.Match(seg) {
+				t.Errorf("Unexpected match of %s (Type: %s)", seg, testCase.kind)
+			}
+		}
+	}
+}
+
+func TestRouterSegmentTypes(t *testing.T) {
+	router := Classic()
+	router.RegisterSegmentType("lang", SegmentType{
+		Validate: func(seg string) bool { return seg == "en" || seg == "de" },
+		Rank:     100,
+	})
+
+	handler := func(key string) func(w http.ResponseWriter, r *http.Request) {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(key))
+			if id, ok := GetRouteValues(r)["id"].(int64); ok && id != 42 {
+				t.Errorf("Unexpected converted value (Expected: 42, Actual: %d)", id)
+			}
+		}
+	}
+
+	router.Get("/docs/{id:int64}", handler("int64"))
+	router.Get("/docs/:string", handler("string"))
+	router.Get("/docs/:uuid", handler("uuid"))
+	router.Get("/docs/:lang", handler("lang"))
+
+	testCases := map[string]string{
+		"/docs/42": "int64",
+		"/docs/6ba7b810-9dad-11d1-80b4-00c04fd430c8": "uuid",
+		"/docs/de":     "lang",
+		"/docs/golang": "string",
+	}
+
+	for path, key := range testCases {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if res.Body.String() != key {
+			t.Errorf("Unexpected route for %s (Expected: %s, Actual: %s)", path, key, res.Body.String())
+		}
+	}
+
+	router.Get("/users/{id:number}", handler("number"))
+	for _, path := range []string{"/users/abc1", "/users/1abc"} {
+		if _, err := router.Lookup(http.MethodGet, path); err != ErrNotFound {
+			t.Errorf("Unexpected match of %s", path)
+		}
+	}
+
+	defer func() {
+		if err := recover(); err == nil || !strings.Contains(err.(string), "not registered") {
+			t.Errorf("Expected a panic for an unknown segment type (%v)", err)
+		}
+	}()
+	router.Get("/docs/:unknown", handler("unknown"))
+}
+
+func TestMixedSegments(t *testing.T) {
+	router := Classic()
+
+	var params map[string]string
+	var values map[string]interface{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		params = GetRouteParameters(r)
+		values = GetRouteValues(r)
+	}
+
+	router.Get("/files/{name}.{ext}", handler)
+	router.Get("/v{version:int64}/users", handler)
+	router.Get("/img/{id}-{size}.png", handler)
+
+	testCases := []struct {
+		path   string
+		params map[string]string
+	}{
+		{path: "/files/archive.tar.gz", params: map[string]string{"name": "archive.tar", "ext": "gz"}},
+		{path: "/v2/users", params: map[string]string{"version": "2"}},
+		{path: "/img/a-b-large.png", params: map[string]string{"id": "a-b", "size": "large"}},
+	}
+
+	for _, testCase := range testCases {
+		params = nil
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, testCase.path, nil))
+
+		if params == nil {
+			t.Errorf("Unexpected miss of %s", testCase.path)
+			continue
+		}
+
+		for name, value := range testCase.params {
+			if params[name] != value {
+				t.Errorf("%s: Unexpected parameter %s (Expected: %s, Actual: %s)", testCase.path, name, value, params[name])
+			}
+		}
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v2/users", nil))
+	if values["version"] != int64(2) {
+		t.Errorf("Unexpected converted version (%v)", values["version"])
+	}
+
+	for _, path := range []string{"/vx/users", "/files/archive", "/img/a-large.jpg"} {
+		if _, err := router.Lookup(http.MethodGet, path); err != ErrNotFound {
+			t.Errorf("Unexpected match of %s", path)
+		}
+	}
+
+	for _, pattern := range []string{"/files/{name}{ext}", "/files/{name.{ext}", "/files/{name}.{name}"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for pattern %s", pattern)
+				}
+			}()
+			router.Get(pattern, handler)
+		}()
+	}
+}
+
+func TestMixedSegmentTypes(t *testing.T) {
+	router := Classic()
+	router.Get("/v{version:number}/users", func(w http.ResponseWriter, r *http.Request) {})
+	router.Get("/img/{id}-{size:number}.png", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, path := range []string{"/v2/users", "/img/a-b-42.png"} {
+		if _, err := router.Lookup(http.MethodGet, path); err != nil {
+			t.Errorf("Unexpected miss of %s (%v)", path, err)
+		}
+	}
+
+	// typed captures are validated as a whole, not by a matching substring
+	for _, path := range []string{"/vabc1/users", "/v1abc/users", "/img/1-large2.png", "/img/1-2large.png"} {
+		if _, err := router.Lookup(http.MethodGet, path); err != ErrNotFound {
+			t.Errorf("Unexpected match of %s", path)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package trixie
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HandlePattern registers a handler for a pattern in the syntax of http.ServeMux:
+//
+//	[METHOD ][HOST]/[PATH]
+//
+// e.g. "GET /items/{id}", "example.com/static/{path...}" or "POST /items/{$}".
+//
+//   - A pattern without method matches all methods, GET also matches HEAD.
+//   - A pattern with host only matches requests for this host.
+//   - {name} matches a segment and {name...} the rest of the path.
+//   - A path ending with a slash matches all paths below, unless it ends with {$}.
+//     A request for the path without the slash is redirected to it (307 Temporary Redirect),
+//     e.g. /static to /static/ for "/static/" and /exact to /exact/ for "GET /exact/{$}".
+//
+// Differences to http.ServeMux, which follow from the routing rules of the tree:
+//
+//   - A path without trailing slash also matches requests with one, "GET /x" serves /x/.
+//   - A method which isn't registered for a path is answered by the not found handler,
+//     unless MethodNotAllowedHandler is set.
+//   - Patterns are matched by the priority of the tree (see Routing Priority), not by
+//     the most specific pattern, and they are not checked for conflicts.
+//
+// The parameters can be retrieved by name with req.PathValue or trixie.GetRouteParameters.
+// The handler gets the pattern as registered in req.Pattern, e.g. "GET /files/", while the route,
+// its middleware and Match use the translated pattern of the tree, e.g. /files/{...}.
+// HandlePattern panics if the pattern is not valid.
+func (r *Router) HandlePattern(pattern string, handler http.Handler) RouteInterface {
+	method, host, path, root, err := parseServeMuxPattern(pattern)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	if root != "" {
+		handler = redirectSlash(root, handler)
+	}
+	handler = servePattern(pattern, handler)
+
+	route := r.routeConstructor()
+	route.SetPattern(path)
+
+	if host != "" {
+		hr, ok := route.(HostRoute)
+		if !ok {
+			panic(NewBadPathError("Route doesn't support hosts (see HostRoute) " + pattern).Error())
+		}
+		hr.SetHost(host)
+	}
+
+	if method == "" {
+		for m := range Methods.ms {
+			route.AddHandler(m, handler)
+		}
+	} else {
+		route.AddHandler(method, handler)
+		if method == http.MethodGet {
+			route.AddHandler(http.MethodHead, handler)
+		}
+	}
+
+	r.ValidateRoute(route)
+	r.RegisterRoute(route)
+	return route
+}
+
+// HandlePatternFunc registers a handler function for a pattern in the syntax of http.ServeMux.
+// See HandlePattern for more details.
+func (r *Router) HandlePatternFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) RouteInterface {
+	return r.HandlePattern(pattern, http.HandlerFunc(handler))
+}
+
+// servePattern sets the pattern of http.ServeMux syntax as req.Pattern
+func servePattern(pattern string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req.Pattern = pattern
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// redirectSlash redirects a request for the root of a pattern ending with a slash,
+// which the tree matches without the slash, to the path with slash like http.ServeMux.
+func redirectSlash(root string, handler http.Handler) http.Handler {
+	segments := len(splitSegments(root))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		p := req.URL.EscapedPath()
+		if strings.HasSuffix(p, "/") || len(splitSegments(p)) != segments {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		target := p + "/"
+		if req.URL.RawQuery != "" {
+			target += "?" + req.URL.RawQuery
+		}
+		http.Redirect(w, req, target, http.StatusTemporaryRedirect)
+	})
+}
+
+// parseServeMuxPattern splits a pattern of http.ServeMux into method, host and path
+// and translates the path into a pattern of the tree. The root is the path of a
+// pattern ending with a slash or {$}, e.g. /static/, it's empty otherwise.
+func parseServeMuxPattern(pattern string) (method, host, path, root string, err error) {
+	rest := strings.TrimSpace(pattern)
+
+	if i := strings.IndexAny(rest, " \t"); i >= 0 {
+		method, rest = rest[:i], strings.TrimLeft(rest[i:], " \t")
+	}
+
+	i := strings.Index(rest, "/")
+	if i < 0 {
+		return "", "", "", "", NewBadPathError("Pattern has no path " + pattern)
+	}
+	host, path = rest[:i], rest[i:]
+
+	switch {
+	case strings.HasSuffix(path, "/{$}"):
+		// an exact match is the default of the tree
+		path = strings.TrimSuffix(path, "{$}")
+		root = path
+	case strings.HasSuffix(path, "/"):
+		// matches the path and all paths below
+		root = path
+		path += "{...}"
+	}
+
+	if strings.Contains(path, "{$}") {
+		return "", "", "", "", NewBadPathError("{$} is only allowed at the end of " + pattern)
+	}
+
+	return method, strings.ToLower(host), path, root, nil
+}
@@ -0,0 +1,124 @@
+package trixie
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterHandlePattern(t *testing.T) {
+	router := Classic()
+
+	handler := func(key string) func(w http.ResponseWriter, r *http.Request) {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(key + ":" + r.PathValue("id") + r.PathValue("path")))
+		}
+	}
+
+	router.HandlePatternFunc("GET /items/{id}", handler("item"))
+	router.HandlePatternFunc("DELETE /items/{id}", handler("delete"))
+	router.HandlePatternFunc("/static/{path...}", handler("static"))
+	router.HandlePatternFunc("GET /files/", handler("files"))
+	router.HandlePatternFunc("GET /{$}", handler("index"))
+	router.HandlePatternFunc("GET api.example.org/items/{id}", handler("host"))
+	router.HandlePatternFunc("GET /exact/{$}", handler("exact"))
+	router.HandlePatternFunc("/users/{id}/", handler("user"))
+
+	testCases := []struct {
+		method     string
+		url        string
+		statusCode int
+		body       string
+		location   string
+	}{
+		{method: http.MethodGet, url: "/items/7", statusCode: http.StatusOK, body: "item:7"},
+		{method: http.MethodHead, url: "/items/7", statusCode: http.StatusOK, body: "item:7"},
+		{method: http.MethodDelete, url: "/items/7", statusCode: http.StatusOK, body: "delete:7"},
+		{method: http.MethodPost, url: "/items/7", statusCode: http.StatusNotFound},
+		{method: http.MethodPost, url: "/static/css/main.css", statusCode: http.StatusOK, body: "static:css/main.css"},
+		{method: http.MethodGet, url: "/files/", statusCode: http.StatusOK, body: "files:"},
+		{method: http.MethodGet, url: "/files/a/b", statusCode: http.StatusOK, body: "files:"},
+		{method: http.MethodGet, url: "/", statusCode: http.StatusOK, body: "index:"},
+		{method: http.MethodGet, url: "/home", statusCode: http.StatusNotFound},
+		{method: http.MethodGet, url: "http://api.example.org:8080/items/7", statusCode: http.StatusOK, body: "host:7"},
+		{method: http.MethodGet, url: "/files", statusCode: http.StatusTemporaryRedirect, location: "/files/"},
+		{method: http.MethodGet, url: "/exact", statusCode: http.StatusTemporaryRedirect, location: "/exact/"},
+		{method: http.MethodGet, url: "/exact/", statusCode: http.StatusOK, body: "exact:"},
+		{method: http.MethodGet, url: "/exact/a", statusCode: http.StatusNotFound},
+		{method: http.MethodPost, url: "/users/7?tab=1", statusCode: http.StatusTemporaryRedirect, location: "/users/7/?tab=1"},
+		{method: http.MethodPost, url: "/users/7/posts", statusCode: http.StatusOK, body: "user:7"},
+	}
+
+	for _, testCase := range testCases {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(testCase.method, testCase.url, nil))
+
+		if res.Code != testCase.statusCode {
+			t.Errorf("%s %s: Unexpected status code (Expected: %d, Actual: %d)", testCase.method, testCase.url, testCase.statusCode, res.Code)
+			continue
+		}
+
+		if location := res.Header().Get("Location"); location != testCase.location {
+			t.Errorf("%s %s: Unexpected location (Expected: %q, Actual: %q)", testCase.method, testCase.url, testCase.location, location)
+		}
+
+		if testCase.statusCode == http.StatusOK && res.Body.String() != testCase.body {
+			t.Errorf("%s %s: Unexpected body (Expected: %s, Actual: %s)", testCase.method, testCase.url, testCase.body, res.Body.String())
+		}
+	}
+}
+
+func TestRouterHandlePatternRequestPattern(t *testing.T) {
+	router := Classic()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Pattern))
+	}
+
+	router.HandlePatternFunc("GET /items/{id}", handler)
+	router.HandlePatternFunc("DELETE /items/{id}", handler)
+	router.HandlePatternFunc("GET /files/", handler)
+	router.HandlePatternFunc("GET /exact/{$}", handler)
+
+	testCases := []struct {
+		method  string
+		url     string
+		pattern string
+	}{
+		{method: http.MethodGet, url: "/items/7", pattern: "GET /items/{id}"},
+		{method: http.MethodHead, url: "/items/7", pattern: "GET /items/{id}"},
+		{method: http.MethodDelete, url: "/items/7", pattern: "DELETE /items/{id}"},
+		{method: http.MethodGet, url: "/files/a/b", pattern: "GET /files/"},
+		{method: http.MethodGet, url: "/exact/", pattern: "GET /exact/{$}"},
+	}
+
+	for _, testCase := range testCases {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(testCase.method, testCase.url, nil))
+
+		if res.Body.String() != testCase.pattern {
+			t.Errorf("%s %s: Unexpected pattern (Expected: %s, Actual: %s)", testCase.method, testCase.url, testCase.pattern, res.Body.String())
+		}
+	}
+}
+
+func TestRouterHandlePatternInvalid(t *testing.T) {
+	patterns := []string{
+		"GET items",
+		"GET /items/{id}/{id}",
+		"GET /items/{path...}/comments",
+		"GET /items/{$}/comments",
+		"GET /items/{1id}",
+	}
+
+	for _, pattern := range patterns {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for pattern %s", pattern)
+				}
+			}()
+			Classic().HandlePatternFunc(pattern, func(w http.ResponseWriter, r *http.Request) {})
+		}()
+	}
+}
@@ -0,0 +1,234 @@
+package trixie
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the maximum count of suggestions for a path
+const maxSuggestions = 3
+
+// Reasons of a suggestion
+const (
+	// SuggestMethod the path matches but the method is not registered
+	SuggestMethod = "method"
+	// SuggestCase the path matches if the case is ignored
+	SuggestCase = "case"
+	// SuggestSlash the path matches without empty segments (e.g. "//")
+	SuggestSlash = "slash"
+	// SuggestDistance the path is a few segments away from the pattern
+	SuggestDistance = "distance"
+)
+
+// Suggestion is a registered route which is close to a path that didn't match.
+type Suggestion struct {
+	// Pattern of the suggested route
+	Pattern string
+	// Host the route is bound to, it's empty if the route matches all hosts
+	Host string
+	// Methods which are registered for the route
+	Methods []string
+	// Reason describes the difference between path and pattern
+	Reason string
+	// Distance is the edit distance over the segments of path and pattern
+	Distance int
+}
+
+// Suggest returns the registered routes which are closest to method and path.
+// It's meant for requests which didn't match, the best suggestion comes first.
+// Routes bound to a host are suggested as well, see Suggestion.Host.
+func (r *Router) Suggest(method, path string) []Suggestion {
+	trees := make([]RouteTreeInterface, 0, len(r.hostTrees)+1)
+	for _, ht := range r.hostTrees {
+		trees = append(trees, ht.tree)
+	}
+	if r.tree != nil {
+		trees = append(trees, r.tree)
+	}
+
+	return r.suggest(trees, method, path)
+}
+
+// suggest returns the routes of the trees which are closest to method and path.
+func (r *Router) suggest(trees []RouteTreeInterface, method, path string) []Suggestion {
+	pathSegments := splitSegments(path)
+	suggestions := make([]Suggestion, 0)
+
+	for _, tree := range trees {
+		rt, ok := tree.(RoutesTree)
+		if !ok {
+			continue
+		}
+
+		for _, route := range rt.Routes() {
+			suggestion, ok := suggest(method, pathSegments, route, r.getSegmentTypes())
+			if ok {
+				suggestions = append(suggestions, suggestion)
+			}
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Distance != suggestions[j].Distance {
+			return suggestions[i].Distance < suggestions[j].Distance
+		}
+		return suggestions[i].Pattern < suggestions[j].Pattern
+	})
+
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+
+	return suggestions
+}
+
+func suggest(method string, pathSegments []string, route RouteInterface, segmentTypes *SegmentTypes) (Suggestion, bool) {
+	suggestion := Suggestion{
+		Pattern: route.GetPattern(),
+		Host:    hostOf(route),
+		Methods: routeMethods(route),
+	}
+
+	patternSegments := make([]*Node, 0)
+	for _, seg := range splitSegments(fullPattern(route.GetPattern())) {
+		patternSegments = append(patternSegments, initNode(new(Node), seg, segmentTypes))
+	}
+
+	switch {
+	case segmentDistance(pathSegments, patternSegments, (*Node).match) == 0:
+		if route.HasHandler(method) {
+			return suggestion, false
+		}
+		suggestion.Reason = SuggestMethod
+	case segmentDistance(pathSegments, patternSegments, matchFold) == 0:
+		suggestion.Reason = SuggestCase
+	case segmentDistance(withoutEmpty(pathSegments), patternSegments, (*Node).match) == 0:
+		suggestion.Reason = SuggestSlash
+	default:
+		suggestion.Reason = SuggestDistance
+		suggestion.Distance = segmentDistance(pathSegments, patternSegments, matchFold)
+
+		// a suggestion which differs in most of the segments isn't helpful
+		if suggestion.Distance > len(patternSegments) {
+			return suggestion, false
+		}
+	}
+
+	return suggestion, true
+}
+
+// segmentDistance is the levenshtein distance over the segments of path and pattern.
+// A path segment which matches the pattern segment costs nothing,
+// a typo in a static segment costs 1 and any other substitution 2.
+func segmentDistance(pathSegments []string, patternSegments []*Node, equal func(*Node, string) bool) int {
+	return levenshtein(len(pathSegments), len(patternSegments), func(i, j int) int {
+		n := patternSegments[j]
+		if equal(n, pathSegments[i]) {
+			return 0
+		}
+
+		if n.typ == staticNode && typo(pathSegments[i], n.seg) {
+			return 1
+		}
+
+		return 2
+	})
+}
+
+// typo reports whether a and b differ in at most two characters
+func typo(a, b string) bool {
+	return levenshtein(len(a), len(b), func(i, j int) int {
+		if a[i] == b[j] {
+			return 0
+		}
+		return 1
+	}) <= 2
+}
+
+// levenshtein computes the edit distance of two sequences of length n and m,
+// cost returns the cost to substitute the i-th element with the j-th element.
+func levenshtein(n, m int, cost func(i, j int) int) int {
+	prev := make([]int, m+1)
+	curr := make([]int, m+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= n; i++ {
+		curr[0] = i
+		for j := 1; j <= m; j++ {
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost(i-1, j-1))
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[m]
+}
+
+func matchFold(n *Node, currentSeg string) bool {
+	if n.typ == staticNode {
+		return strings.EqualFold(currentSeg, n.seg)
+	}
+	return n.match(currentSeg)
+}
+
+func splitSegments(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return make([]string, 0)
+	}
+	return strings.Split(p, "/")
+}
+
+func withoutEmpty(segments []string) []string {
+	filtered := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		if seg != "" {
+			filtered = append(filtered, seg)
+		}
+	}
+	return filtered
+}
+
+func routeMethods(route RouteInterface) []string {
+	methods := make([]string, 0, len(route.GetHandlers()))
+	for method := range route.GetHandlers() {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// suggester computes the suggestions for a missed request on demand,
+// so a not found handler which doesn't need them doesn't pay for it.
+type suggester struct {
+	router *Router
+	method string
+	host   string
+	path   string
+}
+
+func addSuggester(r *http.Request, router *Router, path string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), suggestionsKey, &suggester{router: router, method: r.Method, host: requestHost(r), path: path}))
+}
+
+// GetSuggestions returns the registered routes closest to the request.
+// This only works when called inside the not found or method not allowed handler
+// of the router.
+func GetSuggestions(r *http.Request) []Suggestion {
+	if rv := r.Context().Value(suggestionsKey); rv != nil {
+		s := rv.(*suggester)
+
+		// only routes which can match the host of the request are suggested
+		trees := make([]RouteTreeInterface, 0, 2)
+		for _, tm := range s.router.treesFor(s.host) {
+			trees = append(trees, tm.tree)
+		}
+
+		return s.router.suggest(trees, s.method, s.path)
+	}
+	return nil
+}
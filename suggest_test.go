@@ -0,0 +1,83 @@
+package trixie
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterSuggest(t *testing.T) {
+	router := Classic()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	router.Get("/api/user/:number", handler)
+	router.Get("/api/article/:string", handler)
+	router.Post("/api/comment", handler)
+	router.Get("/api/Echo", handler)
+
+	testCases := []struct {
+		method  string
+		path    string
+		pattern string
+		reason  string
+	}{
+		{
+			method:  http.MethodGet,
+			path:    "/api/comment",
+			pattern: "/api/comment",
+			reason:  SuggestMethod,
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/api/echo",
+			pattern: "/api/Echo",
+			reason:  SuggestCase,
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/api//user/1",
+			pattern: "/api/user/:number",
+			reason:  SuggestSlash,
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/api/usr/1",
+			pattern: "/api/user/:number",
+			reason:  SuggestDistance,
+		},
+	}
+
+	for _, testCase := range testCases {
+		suggestions := router.Suggest(testCase.method, testCase.path)
+
+		if len(suggestions) == 0 {
+			t.Errorf("Unexpected empty suggestions (Path: %s)", testCase.path)
+			continue
+		}
+
+		if suggestions[0].Pattern != testCase.pattern || suggestions[0].Reason != testCase.reason {
+			t.Errorf("Unexpected suggestion for %s (Expected: %s %s, Actual: %+v)", testCase.path, testCase.pattern, testCase.reason, suggestions[0])
+		}
+	}
+
+	if suggestions := router.Suggest(http.MethodGet, "/home/news/today"); len(suggestions) != 0 {
+		t.Errorf("Unexpected suggestions (%+v)", suggestions)
+	}
+}
+
+func TestRouterSuggestionsInNotFoundHandler(t *testing.T) {
+	router := Classic()
+	router.Get("/api/user/:number", func(w http.ResponseWriter, r *http.Request) {})
+
+	var suggestions []Suggestion
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suggestions = GetSuggestions(r)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/users/1", nil))
+
+	if len(suggestions) != 1 || suggestions[0].Pattern != "/api/user/:number" {
+		t.Errorf("Unexpected suggestions (%+v)", suggestions)
+	}
+}
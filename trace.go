@@ -0,0 +1,88 @@
+package trixie
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Trace records how a path was looked up in a tree.
+// It's returned by Explain and meant for debugging of the routing.
+type Trace struct {
+	// Method of the request, only set by Router.Explain
+	Method string
+	// Path which was looked up
+	Path string
+	// Steps are the comparisons in the order they were performed
+	Steps []TraceStep
+	// Route is the matched route, it's nil if the path didn't match
+	Route RouteInterface
+	// Params are the parameters of the path
+	Params map[string]string
+	// Err is the reason of a miss
+	Err error
+}
+
+// TraceStep is a comparison of a path segment with a node of the tree.
+type TraceStep struct {
+	// Depth is the index of the path segment
+	Depth int
+	// Segment of the path
+	Segment string
+	// Node is the segment of the node (pattern)
+	Node string
+	// Type of the node (static, param or regex)
+	Type string
+	// Comparison describes the performed comparison
+	Comparison string
+	// Matched is the result of the comparison
+	Matched bool
+	// Rejected explains why a matched node was rejected
+	Rejected string
+}
+
+func (t *Trace) add(depth int, currentSeg string, n *Node, matched bool) {
+	if t == nil {
+		return
+	}
+
+	t.Steps = append(t.Steps, TraceStep{
+		Depth:      depth,
+		Segment:    currentSeg,
+		Node:       n.seg,
+		Type:       n.typ.String(),
+		Comparison: compare(n, currentSeg),
+		Matched:    matched,
+	})
+}
+
+func (t *Trace) reject(reason string) {
+	if t == nil || len(t.Steps) == 0 {
+		return
+	}
+
+	t.Steps[len(t.Steps)-1].Rejected = reason
+}
+
+// String returns a human readable form of the trace
+func (t *Trace) String() string {
+	buf := new(bytes.Buffer)
+
+	fmt.Fprintf(buf, "%s %s\n", t.Method, t.Path)
+	for _, step := range t.Steps {
+		result := "no match"
+		if step.Rejected != "" {
+			result = "rejected: " + step.Rejected
+		} else if step.Matched {
+			result = "match"
+		}
+		fmt.Fprintf(buf, "  [%d] %s node %q: %s -> %s\n", step.Depth, step.Type, step.Node, step.Comparison, result)
+	}
+
+	if t.Err != nil {
+		fmt.Fprintf(buf, "result: %s\n", t.Err.Error())
+	} else if t.Route != nil {
+		fmt.Fprintf(buf, "result: %s\n", t.Route.GetPattern())
+	}
+
+	return buf.String()
+}
@@ -15,9 +15,28 @@ type RouteTreeInterface interface {
 	GetRoot() *Node
 }
 
+// The following interfaces are optional abilities of a tree, the router checks
+// for them by type assertion (see Router.UseTree).
+
+// ExplainTree is a tree which records the lookup of a path, see Router.Explain
+type ExplainTree interface {
+	Explain(*Node, string) *Trace
+}
+
+// RoutesTree is a tree which lists its routes, see Router.Suggest
+type RoutesTree interface {
+	Routes() []RouteInterface
+}
+
+// SegmentTypesTree is a tree which matches the segment types of the router, see Router.RegisterSegmentType
+type SegmentTypesTree interface {
+	UseSegmentTypes(*SegmentTypes)
+}
+
 type Tree struct {
 	root            *Node
 	nodeConstructor func() *Node
+	segmentTypes    *SegmentTypes
 }
 
 // NewTree returns an empty Radix Tree
@@ -37,6 +56,12 @@ func (t *Tree) UseNode(constructer func() *Node) {
 	t.nodeConstructor = constructer
 }
 
+// UseSegmentTypes that you can use your own parameter types (e.g. :uuid)
+// See SegmentTypes for more details (segment.go)
+func (t *Tree) UseSegmentTypes(segmentTypes *SegmentTypes) {
+	t.segmentTypes = segmentTypes
+}
+
 func (t *Tree) GetRoot() *Node {
 	return t.root
 }
@@ -47,210 +72,398 @@ func (t *Tree) pathSegments(p string) []string {
 
 // Insert is used to add a new entry or update
 // an existing entry.
+//
+// A route with optional segments (e.g. /posts/{page?}) is added for every
+// expansion of its pattern. Insert panics if an expansion meets a route with another
+// pattern, since it's unclear which one should be matched.
 func (t *Tree) Insert(newRoute RouteInterface) RouteInterface {
 
-	if newRoute.GetPattern() == "/" {
-		if t.root.leaf == nil {
-			t.root.leaf = newRoute
-		} else {
-			t.root.leaf = mergeRoutes(t.root.leaf, newRoute)
-		}
+	op, err := parseOptionalPattern(newRoute.GetPattern())
+	if err != nil || len(op.groups) == 0 {
+		t.insert(newRoute, newRoute.GetPattern(), nil)
 		return newRoute
 	}
 
+	patterns := op.expand()
+
+	// all expansions are checked first, so a conflict leaves the tree unchanged
+	for _, pattern := range patterns {
+		if n := t.existingNode(pattern); n != nil && n.leaf != nil && conflicts(n.leaf, newRoute) {
+			panic(NewRouteConflictError(newRoute.GetPattern(), n.leaf.GetPattern()).Error())
+		}
+	}
+
+	for _, pattern := range patterns {
+		t.insert(newRoute, pattern, op.absentDefaults(pattern))
+	}
+
+	return newRoute
+}
+
+// existingNode returns the node of the pattern, it's nil if the node doesn't exist yet.
+func (t *Tree) existingNode(pattern string) *Node {
 	currentNode := t.root
-	pathSegments := t.pathSegments(newRoute.GetPattern())
-	currentSeg := pathSegments[0]
-	currentSegTyp := NodeOfType(currentSeg)
-	var nextSeg string
-	next := true
-	if len(pathSegments) > 1 {
-		pathSegments = pathSegments[1:]
-	} else {
-		pathSegments = make([]string, 0, 0)
-		next = false
-	}
-
-	for {
-
-		if !next {
-			if currentNode.root {
-				n := NewNode()
-				n.seg = currentSeg
-				n.leaf = newRoute
-				currentNode.nodes[currentSegTyp] = append(currentNode.nodes[currentSegTyp], n)
-				return newRoute
-			} else if currentSeg == currentNode.seg {
-
-				if currentNode.leaf == nil {
-					currentNode.leaf = newRoute
-				} else {
-					currentNode.leaf = mergeRoutes(currentNode.leaf, newRoute)
-				}
 
-				return newRoute
+	if pattern == "/" {
+		return currentNode
+	}
+
+	for _, seg := range t.pathSegments(pattern) {
+		var next *Node
+		for _, sub := range currentNode.nodes[NodeOfType(seg)] {
+			if sub.seg == seg {
+				next = sub
+				break
 			}
 		}
 
-		if nextSeg != "" {
-			currentSeg = nextSeg
-			currentSegTyp = NodeOfType(currentSeg)
+		if next == nil {
+			return nil
 		}
+		currentNode = next
+	}
 
-	outerLoop:
-		for typ, nodes := range currentNode.nodes {
+	return currentNode
+}
 
-			if nodeType(typ) != currentSegTyp {
-				continue
-			}
+func (t *Tree) insert(newRoute RouteInterface, pattern string, defaults map[string]string) {
 
-			for _, n := range nodes {
-				if n.seg == currentSeg {
-					if len(pathSegments) == 1 {
-						nextSeg = pathSegments[0]
-						currentNode = n
-						pathSegments = make([]string, 0, 0)
-					} else if len(pathSegments) > 1 {
-						nextSeg = pathSegments[0]
-						pathSegments = pathSegments[1:]
-						currentNode = n
-					} else if len(pathSegments) == 0 {
-						currentNode = n
-						next = false
-					}
-
-					break outerLoop
-				}
-			}
+	currentNode := t.root
 
-			n := NewNode()
-			currentSegTyp := NodeOfType(currentSeg)
-			n.seg = currentSeg
-			currentNode.nodes[currentSegTyp] = append(currentNode.nodes[currentSegTyp], n)
-			currentNode = n
-
-			for _, seg := range pathSegments {
-				currentSegTyp := NodeOfType(seg)
-				n := NewNode()
-				n.seg = seg
-				currentNode.nodes[currentSegTyp] = append(currentNode.nodes[currentSegTyp], n)
-				currentNode = n
-			}
+	if pattern != "/" {
+		for _, seg := range t.pathSegments(pattern) {
+			currentNode = t.subNode(currentNode, seg)
+		}
+	}
 
-			currentNode.leaf = newRoute
-			return newRoute
+	if currentNode.leaf == nil {
+		currentNode.leaf = newRoute
+		currentNode.defaults = defaults
+		return
+	}
+
+	if conflicts(currentNode.leaf, newRoute) {
+		panic(NewRouteConflictError(newRoute.GetPattern(), currentNode.leaf.GetPattern()).Error())
+	}
+
+	currentNode.leaf = mergeRoutes(currentNode.leaf, newRoute)
+}
+
+// conflicts reports whether two routes with optional segments can't share a node
+func conflicts(a, b RouteInterface) bool {
+	if a.GetPattern() == b.GetPattern() {
+		return false
+	}
+	return hasOptionalSegments(a.GetPattern()) || hasOptionalSegments(b.GetPattern())
+}
+
+// subNode returns the sub node of n for the segment, it's created if it doesn't exist.
+// Param nodes are kept in order of the priority of their segment types.
+func (t *Tree) subNode(n *Node, seg string) *Node {
+	typ := NodeOfType(seg)
+
+	for _, sub := range n.nodes[typ] {
+		if sub.seg == seg {
+			return sub
 		}
 	}
 
+	segmentTypes := t.segmentTypes
+	if segmentTypes == nil {
+		segmentTypes = defaultSegmentTypes
+	}
+
+	sub := initNode(t.nodeConstructor(), seg, segmentTypes)
+
+	i := len(n.nodes[typ])
+	for typ == paramNode && i > 0 && sub.priority() > n.nodes[typ][i-1].priority() {
+		i--
+	}
+
+	n.nodes[typ] = append(n.nodes[typ], nil)
+	copy(n.nodes[typ][i+1:], n.nodes[typ][i:])
+	n.nodes[typ][i] = sub
+
+	return sub
 }
 
 // Find is used to lookup a specific key, returning
 // the value and if it was found
 func (t *Tree) Find(root *Node, path string) (RouteInterface, map[string]string, error) {
+	return t.find(root, path, nil)
+}
+
+// Explain looks up the path like Find and records every
+// visited node and the comparison performed against it.
+func (t *Tree) Explain(root *Node, path string) *Trace {
+	trace := &Trace{Path: path}
+	trace.Route, trace.Params, trace.Err = t.find(root, path, trace)
+	return trace
+}
+
+func (t *Tree) find(root *Node, path string, trace *Trace) (RouteInterface, map[string]string, error) {
 
 	if path == "" {
 		return nil, nil, errors.New("empty path")
 	}
 
 	if path == "/" {
-		if t.root.leaf == nil {
-			return nil, nil, errors.New("root is not a leaf")
+		if t.root.leaf != nil {
+			return t.root.leaf, withDefaults(nil, t.root), nil
+		}
+
+		if n := catchAllLeaf(t.root, 0, trace); n != nil {
+			return n.leaf, buildParams(nil, []*Node{n}), nil
 		}
-		
-		return t.root.leaf, nil, nil
+
+		return nil, nil, errors.New("root is not a leaf")
 	}
 
 	pathSegments := t.pathSegments(path)
-	currentSeg := pathSegments[0]
-	currentNode := *t.root
-	copyPathSegments := pathSegments
+	if route, params, found := t.findBelow(t.root, pathSegments, make([]*Node, 0, len(pathSegments)), trace); found {
+		return route, params, nil
+	}
+
+	return nil, nil, errors.New("path not found")
+}
 
-	if len(pathSegments) > 1 {
-		pathSegments = pathSegments[1:]
-	} else {
-		pathSegments = make([]string, 0, 0)
+// findBelow matches the path segment at the depth of visited against the sub nodes of n.
+// The sub nodes are tried in order of their type and priority, if the rest of the path
+// doesn't match below a node the lookup goes back and continues with the next node.
+func (t *Tree) findBelow(n *Node, pathSegments []string, visited []*Node, trace *Trace) (RouteInterface, map[string]string, bool) {
+	depth := len(visited)
+	currentSeg := pathSegments[depth]
+
+	for _, typ := range []nodeType{regexNode, staticNode, mixedNode, paramNode, catchAllNode} {
+		for _, sub := range n.nodes[typ] {
+			matched := sub.match(currentSeg)
+			trace.add(depth, currentSeg, sub, matched)
+
+			if !matched {
+				continue
+			}
+
+			// a catch-all node consumes the rest of the path
+			if typ == catchAllNode {
+				return sub.leaf, buildParams(pathSegments, append(visited, sub)), true
+			}
+
+			if depth == len(pathSegments)-1 {
+				if sub.leaf != nil {
+					return sub.leaf, withDefaults(buildParams(pathSegments, append(visited, sub)), sub), true
+				}
+
+				// a catch-all sub node also matches an empty rest
+				if c := catchAllLeaf(sub, depth+1, trace); c != nil {
+					return c.leaf, buildParams(pathSegments, append(visited, sub, c)), true
+				}
+
+				// an inner node without route can't end a path
+				trace.reject("node holds no route")
+				continue
+			}
+
+			if route, params, found := t.findBelow(sub, pathSegments, append(visited, sub), trace); found {
+				return route, params, true
+			}
+		}
+	}
+
+	return nil, nil, false
+}
+
+// catchAllLeaf returns the first catch-all sub node of n, which holds a route
+func catchAllLeaf(n *Node, depth int, trace *Trace) *Node {
+	for _, c := range n.nodes[catchAllNode] {
+		trace.add(depth, "", c, c.leaf != nil)
+		if c.leaf != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// buildParams returns the parameters of a path, every segment is stored as segN
+// and the segments of named nodes are also stored under the name of the node.
+func buildParams(pathSegments []string, visited []*Node) map[string]string {
+	param := map[string]string{}
+
+	for key, seg := range pathSegments {
+		param[fmt.Sprintf("seg%d", key)] = seg
 	}
 
-	for {
-		if !hasSubNodes(&currentNode) {
-			break
+	for depth, n := range visited {
+		if n.typ == regexNode {
+			addGroups(param, depth, n, pathSegments[depth])
+			continue
 		}
 
-	outerLoop:
-		for _, typ := range []nodeType{regexNode, staticNode, paramNode} {
-			for _, n := range currentNode.nodes[typ] {
-				if match(typ, currentSeg, n.seg) {
-					if len(pathSegments) == 0 {
-						param := map[string]string{}
-						for key, seg := range copyPathSegments {
-							param[fmt.Sprintf("seg%d", key)] = seg
-						}
-						return n.leaf, param, nil
-					} else if len(pathSegments) > 1 {
-						currentSeg = pathSegments[0]
-						pathSegments = pathSegments[1:]
-						currentNode = *n
-					} else if len(pathSegments) == 1 {
-						currentSeg = pathSegments[0]
-						pathSegments = make([]string, 0, 0)
-						currentNode = *n
-					}
-					break outerLoop
+		if n.typ == mixedNode {
+			captures := make([]string, len(n.parts))
+			matchParts(n.parts, pathSegments[depth], captures)
+			i := 0
+			for _, part := range n.parts {
+				if part.capture {
+					param[part.name] = captures[i]
+					i++
 				}
 			}
-			currentNode.nodes[typ] = nil
+			continue
+		}
+
+		if n.name == "" {
+			continue
+		}
+
+		if n.typ == catchAllNode {
+			param[n.name] = strings.Join(pathSegments[depth:], "/")
+		} else {
+			param[n.name] = pathSegments[depth]
 		}
 	}
 
-	return nil, nil, errors.New("path not found")
+	return param
 }
 
-func hasSubNodes(n *Node) bool {
-	if len(n.nodes[0]) == 0 && len(n.nodes[1]) == 0 && len(n.nodes[2]) == 0 {
-		return false
+// withDefaults adds the defaults of the absent optional params of a node
+func withDefaults(param map[string]string, n *Node) map[string]string {
+	if len(n.defaults) == 0 {
+		return param
 	}
-	return true
-}
 
-func NodeOfType(seg string) nodeType {
-	var segTyp nodeType
-	if seg == ":string" || seg == ":number" {
-		segTyp = paramNode
-	} else if len(seg) > 0 && string(seg[0]) == "#" {
-		segTyp = regexNode
-	} else {
-		segTyp = staticNode
+	if param == nil {
+		param = map[string]string{}
+	}
+
+	for name, def := range n.defaults {
+		param[name] = def
 	}
 
-	return segTyp
+	return param
 }
 
-func match(typ nodeType, currentSeg, seg string) (matched bool) {
-	if regexNode == nodeType(typ) {
-		if match, err := regexp.MatchString(seg[1:], currentSeg); err == nil && match {
-			matched = true
-		}
-	} else if paramNode == nodeType(typ) && seg == ":string" {
-		if match, err := regexp.MatchString("([a-zA-Z]{1,})", currentSeg); err == nil && match {
-			matched = true
+// addGroups stores the capture groups of a regex node, named groups under
+// their name and unnamed groups by index as segN.I (e.g. seg1.1 for the first group of seg1).
+func addGroups(param map[string]string, depth int, n *Node, seg string) {
+	if n.regexp == nil || n.regexp.NumSubexp() == 0 {
+		return
+	}
+
+	groups := n.regexp.FindStringSubmatch(seg)
+	if groups == nil {
+		return
+	}
+
+	for i, name := range n.regexp.SubexpNames()[1:] {
+		if name == "" {
+			name = fmt.Sprintf("seg%d.%d", depth, i+1)
 		}
-	} else if paramNode == nodeType(typ) && seg == ":number" {
-		if match, err := regexp.MatchString("([0-9]{1,})", currentSeg); err == nil && match {
-			matched = true
+		param[name] = groups[i+1]
+	}
+}
+
+// Routes returns all routes stored in the tree
+func (t *Tree) Routes() []RouteInterface {
+	return collectRoutes(t.root, make([]RouteInterface, 0))
+}
+
+func collectRoutes(n *Node, routes []RouteInterface) []RouteInterface {
+	if n.leaf != nil {
+		routes = append(routes, n.leaf)
+	}
+
+	for _, nodes := range n.nodes {
+		for _, sub := range nodes {
+			routes = collectRoutes(sub, routes)
 		}
-	} else if staticNode == nodeType(typ) {
-		if seg == currentSeg {
-			matched = true
+	}
+
+	return routes
+}
+
+func NodeOfType(seg string) nodeType {
+	typ, _, _ := parseSegment(seg)
+	return typ
+}
+
+// initNode sets up a node for the segment of a pattern
+func initNode(n *Node, seg string, segmentTypes *SegmentTypes) *Node {
+	var kind string
+
+	n.seg = seg
+	n.typ, n.name, kind = parseSegment(seg)
+
+	switch n.typ {
+	case paramNode:
+		n.kind = kind
+		n.matcher = segmentTypes.matcher(kind)
+	case mixedNode:
+		// an invalid segment never matches, it's rejected by the segment validator
+		n.parts, _ = parseMixedSegment(seg)
+		for i := range n.parts {
+			if n.parts[i].capture {
+				n.parts[i].matcher = segmentTypes.matcher(n.parts[i].kind)
+			}
 		}
+	case regexNode:
+		// an invalid expression never matches
+		n.regexp, _ = regexp.Compile(seg[1:])
+	}
+
+	return n
+}
+
+// match reports whether the path segment matches the node
+func (n *Node) match(currentSeg string) bool {
+	switch n.typ {
+	case regexNode:
+		return n.regexp != nil && n.regexp.MatchString(currentSeg)
+	case paramNode:
+		return n.matcher != nil && n.matcher.Match(currentSeg)
+	case mixedNode:
+		return n.parts != nil && matchParts(n.parts, currentSeg, nil)
+	case catchAllNode:
+		return true
 	}
-	return matched
+	return n.seg == currentSeg
 }
 
+// priority of the node among its siblings of the same type
+func (n *Node) priority() int {
+	if n.matcher == nil {
+		return 0
+	}
+	return n.matcher.Priority()
+}
+
+// compare describes the comparison performed by match
+func compare(n *Node, currentSeg string) string {
+	switch {
+	case regexNode == n.typ:
+		return fmt.Sprintf("%q =~ /%s/", currentSeg, n.seg[1:])
+	case paramNode == n.typ && n.kind != "":
+		return fmt.Sprintf("%q is a %s", currentSeg, n.kind)
+	case paramNode == n.typ:
+		return fmt.Sprintf("%q is not empty", currentSeg)
+	case catchAllNode == n.typ:
+		return fmt.Sprintf("%q and the rest of the path", currentSeg)
+	case mixedNode == n.typ:
+		return fmt.Sprintf("%q matches %s", currentSeg, n.seg)
+	}
+	return fmt.Sprintf("%q == %q", currentSeg, n.seg)
+}
+
+// mergeRoutes groups routes which share a pattern, each route keeps its handlers and conditions.
 func mergeRoutes(routes ...RouteInterface) RouteInterface {
+	group := new(routeGroup)
 
-	for i := 1; i <= len(routes)-1; i++ {
-		routes[0].AddHandlers(routes[i].GetHandlers())
+	for _, route := range routes {
+		if g, ok := route.(*routeGroup); ok {
+			group.routes = append(group.routes, g.routes...)
+			continue
+		}
+		group.routes = append(group.routes, route)
 	}
 
-	return routes[0]
+	return group
 }
@@ -44,7 +44,7 @@ var treeRouteTestCases = []routeTestCase{
 	{
 		rawPath:      "/#([0-9]{3,})/:number/:number/:number/:number",
 		path:         "/140/1/1/1/1",
-		countOfParam: 5,
+		countOfParam: 6, // 5 segments and the group of the regex (seg0.1)
 	},
 }
 
@@ -157,3 +157,119 @@ func BenchmarkTestTree_find(b *testing.B) {
 		})
 	}
 }
+
+func TestTreeNamedSegments(t *testing.T) {
+	tree := NewTree(NewNode)()
+
+	for _, pattern := range []string{"/items/{id}", "/items/{id}/comments", "/static/{path...}"} {
+		route := NewRoute()
+		route.SetPattern(pattern)
+		tree.Insert(route)
+	}
+
+	testCases := []struct {
+		path    string
+		pattern string
+		name    string
+		value   string
+	}{
+		{path: "/items/7", pattern: "/items/{id}", name: "id", value: "7"},
+		{path: "/items/7/comments", pattern: "/items/{id}/comments", name: "id", value: "7"},
+		{path: "/static/css/main.css", pattern: "/static/{path...}", name: "path", value: "css/main.css"},
+		{path: "/static", pattern: "/static/{path...}", name: "path", value: ""},
+	}
+
+	for _, testCase := range testCases {
+		route, params, err := tree.Find(tree.GetRoot(), testCase.path)
+		if err != nil {
+			t.Errorf("Unexpected error for %s (%s)", testCase.path, err.Error())
+			continue
+		}
+
+		if route.GetPattern() != testCase.pattern {
+			t.Errorf("Unexpected route (Expected: %s, Actual: %s)", testCase.pattern, route.GetPattern())
+		}
+
+		if value, found := params[testCase.name]; !found || value != testCase.value {
+			t.Errorf("Unexpected parameter %s (Expected: %s, Actual: %s)", testCase.name, testCase.value, value)
+		}
+	}
+}
+
+func TestTreeInsertMergesRoutes(t *testing.T) {
+	tree := NewTree(NewNode)()
+
+	tree.Insert(NewRoute().SetPattern("/api").AddHandlerFunc("GET", nil))
+	tree.Insert(NewRoute().SetPattern("/api").AddHandlerFunc("POST", nil))
+
+	route, _, err := tree.Find(tree.GetRoot(), "/api")
+	if err != nil {
+		t.Fatalf("Unexpected error (%s)", err.Error())
+	}
+
+	if !route.HasHandler("GET") || !route.HasHandler("POST") {
+		t.Errorf("Unexpected handlers (%v)", route.GetHandlers())
+	}
+}
+
+func TestTreeRegexGroups(t *testing.T) {
+	tree := NewTree(NewNode)()
+	tree.Insert(NewRoute().SetPattern(`/archive/#(?P<year>\d{4})-(?P<month>\d{2})/#([a-z]+)_([0-9]+)`))
+
+	_, params, err := tree.Find(tree.GetRoot(), "/archive/2017-03/post_7")
+	if err != nil {
+		t.Fatalf("Unexpected error (%s)", err.Error())
+	}
+
+	expected := map[string]string{
+		"year":   "2017",
+		"month":  "03",
+		"seg2.1": "post",
+		"seg2.2": "7",
+	}
+
+	for name, value := range expected {
+		if params[name] != value {
+			t.Errorf("Unexpected parameter %s (Expected: %s, Actual: %s)", name, value, params[name])
+		}
+	}
+}
+
+func TestTreeFindBacktracks(t *testing.T) {
+	tree := NewTree(NewNode)()
+
+	for _, pattern := range []string{"/a/{id:number(1..5)}/x", "/a/{id}/y", "/b/new/edit", "/b/{id}/show"} {
+		tree.Insert(NewRoute().SetPattern(pattern))
+	}
+
+	testCases := []struct {
+		path    string
+		pattern string
+		id      string
+	}{
+		{path: "/a/3/x", pattern: "/a/{id:number(1..5)}/x", id: "3"},
+		{path: "/a/3/y", pattern: "/a/{id}/y", id: "3"},
+		{path: "/b/new/edit", pattern: "/b/new/edit"},
+		{path: "/b/new/show", pattern: "/b/{id}/show", id: "new"},
+	}
+
+	for _, testCase := range testCases {
+		route, params, err := tree.Find(tree.GetRoot(), testCase.path)
+		if err != nil {
+			t.Errorf("Unexpected error for %s (%s)", testCase.path, err.Error())
+			continue
+		}
+
+		if route.GetPattern() != testCase.pattern {
+			t.Errorf("Unexpected route for %s (Expected: %s, Actual: %s)", testCase.path, testCase.pattern, route.GetPattern())
+		}
+
+		if params["id"] != testCase.id {
+			t.Errorf("Unexpected parameter id for %s (Expected: %s, Actual: %s)", testCase.path, testCase.id, params["id"])
+		}
+	}
+
+	if _, _, err := tree.Find(tree.GetRoot(), "/a/9/x"); err == nil {
+		t.Error("Unexpected match of /a/9/x")
+	}
+}
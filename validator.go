@@ -1,5 +1,13 @@
 package trixie
 
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
 //Validator validates the incomming value against a valid value/s
 type Validator interface {
 	Validate(RouteInterface) error
@@ -25,6 +33,220 @@ func (v pathValidator) Validate(r RouteInterface) error {
 	return nil
 }
 
+//segmentValidator checks the param segments :type, {name}, {name:type}, {name...},
+//the captures of mixed segments like {name}.{ext}, the defaults of optional params
+//and the regex segments of a path as well as the labels of the host.
+type segmentValidator struct {
+	// segmentTypes are used to check the defaults, the built-in types if nil
+	segmentTypes *SegmentTypes
+}
+
+func NewSegmentValidator() segmentValidator {
+	return segmentValidator{}
+}
+
+func (v segmentValidator) Validate(r RouteInterface) error {
+
+	// optional segments are validated in the longest expansion of the pattern
+	patterns, err := expandPattern(r.GetPattern())
+	if err != nil {
+		return err
+	}
+
+	names := map[string]struct{}{}
+	segments := strings.Split(strings.Trim(patterns[len(patterns)-1], "/"), "/")
+
+	for i, seg := range segments {
+		typ, name, kind := parseSegment(seg)
+
+		switch typ {
+		case staticNode:
+			if strings.Contains(seg, "}") {
+				return NewBadPathError(fmt.Sprintf("Segment %s has unbalanced braces", seg))
+			}
+			continue
+		case regexNode:
+			re, err := regexp.Compile(seg[1:])
+			if err != nil {
+				return NewBadPathError(fmt.Sprintf("Segment %s is not a valid expression", seg))
+			}
+
+			// named groups become parameters
+			for _, group := range re.SubexpNames()[1:] {
+				if group == "" {
+					continue
+				}
+				if _, found := names[group]; found {
+					return NewBadPathError(fmt.Sprintf("Name %s is used twice", group))
+				}
+				names[group] = struct{}{}
+			}
+			continue
+		case catchAllNode:
+			if i != len(segments)-1 {
+				return NewBadPathError(fmt.Sprintf("Segment %s is not the last segment", seg))
+			}
+			// the rest of the path can be matched without name
+			if name == "" {
+				continue
+			}
+		case paramNode:
+			// :type is unnamed
+			if strings.HasPrefix(seg, ":") {
+				if err := validateKind(seg, kind); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if err := validateCaptures(seg, typ, name, kind, names); err != nil {
+			return err
+		}
+	}
+
+	if err := v.validateDefaults(r.GetPattern(), segments); err != nil {
+		return err
+	}
+
+	return validateHost(hostOf(r), names)
+}
+
+// validateDefaults checks that the defaults of optional params like {page:number(1..)=1}
+// match the type of the param and its constraint.
+func (v segmentValidator) validateDefaults(pattern string, segments []string) error {
+	op, err := parseOptionalPattern(pattern)
+	if err != nil {
+		return err
+	}
+
+	segmentTypes := v.segmentTypes
+	if segmentTypes == nil {
+		segmentTypes = defaultSegmentTypes
+	}
+
+	for _, seg := range segments {
+		typ, name, kind := parseSegment(seg)
+		def, found := op.defaults[name]
+		if typ != paramNode || !found {
+			continue
+		}
+
+		// types which aren't registered are reported by the router
+		matcher := segmentTypes.matcher(kind)
+		if matcher != nil && !matcher.Match(def) {
+			return NewBadPathError(fmt.Sprintf("Segment %s has a default %s which doesn't match its type", seg, def))
+		}
+	}
+
+	return nil
+}
+
+// validateHost checks the labels of a host pattern like {tenant}.example.com,
+// the names of its params have to differ from the names of the path params.
+func validateHost(host string, names map[string]struct{}) error {
+	if host == "" {
+		return nil
+	}
+
+	hostname, port := splitHostPort(host)
+	if _, err := strconv.ParseUint(port, 10, 16); port != "" && err != nil {
+		return NewBadPathError(fmt.Sprintf("Host %s has no valid port", host))
+	}
+
+	for _, label := range strings.Split(hostname, ".") {
+		typ, name, kind := parseSegment(label)
+
+		switch typ {
+		case staticNode:
+			if label == "" || strings.ContainsAny(label, "}/") {
+				return NewBadPathError(fmt.Sprintf("Host %s has an invalid label %s", host, label))
+			}
+			continue
+		case regexNode, catchAllNode:
+			return NewBadPathError(fmt.Sprintf("Host %s has an invalid label %s", host, label))
+		}
+
+		if err := validateCaptures(label, typ, name, kind, names); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateCaptures checks the names and types of a param segment or the captures of a mixed segment
+func validateCaptures(seg string, typ nodeType, name, kind string, names map[string]struct{}) error {
+	captures := []segmentPart{{capture: true, name: name, kind: kind}}
+	if typ == mixedNode {
+		parts, err := parseMixedSegment(seg)
+		if err != nil {
+			return err
+		}
+		captures = parts
+	}
+
+	for _, capture := range captures {
+		if !capture.capture {
+			continue
+		}
+
+		if !isIdentifier(capture.name) {
+			return NewBadPathError(fmt.Sprintf("Segment %s has no valid name", seg))
+		}
+
+		if capture.kind != "" {
+			if err := validateKind(seg, capture.kind); err != nil {
+				return err
+			}
+		}
+
+		if _, found := names[capture.name]; found {
+			return NewBadPathError(fmt.Sprintf("Name %s is used twice", capture.name))
+		}
+		names[capture.name] = struct{}{}
+	}
+
+	return nil
+}
+
+// validateKind checks the type of a param segment and its constraint, e.g. number(1..999999)
+func validateKind(seg, kind string) error {
+	name, args, constrained := splitKind(kind)
+	if !isIdentifier(name) {
+		return NewBadPathError(fmt.Sprintf("Segment %s has no valid type", seg))
+	}
+
+	if name == "in" && !constrained {
+		return NewBadPathError(fmt.Sprintf("Segment %s has no values", seg))
+	}
+
+	if !constrained {
+		return nil
+	}
+
+	if _, err := parseConstraint(name, args); err != nil {
+		return NewBadPathError(fmt.Sprintf("Segment %s has no valid constraint (%s)", seg, err.Error()))
+	}
+
+	return nil
+}
+
+// isIdentifier reports whether s is a valid Go identifier
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, c := range s {
+		if !unicode.IsLetter(c) && c != '_' && (i == 0 || !unicode.IsDigit(c)) {
+			return false
+		}
+	}
+
+	return true
+}
+
 //methodValidator check if method is a correct value.
 type methodValidator struct{}
 
@@ -45,5 +267,6 @@ func (v methodValidator) Validate(r RouteInterface) error {
 
 var Validatoren = []Validator{
 	NewPathValidator(),
+	NewSegmentValidator(),
 	NewMethodValidator(),
 }
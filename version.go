@@ -0,0 +1,76 @@
+package trixie
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// vendorVersion finds the version of a vendor media type like application/vnd.acme.v2+json
+var vendorVersion = regexp.MustCompile(`^[a-z]+/vnd\.[^+]*\.v([0-9]+(?:\.[0-9]+)*)(?:\+|$)`)
+
+// acceptVersions returns the API versions a request accepts, the most preferred first.
+//
+// The version is taken from vendor media types (application/vnd.acme.v2+json)
+// or the version parameter (application/json; version=2). An empty version stands
+// for a media type without version, e.g. */* or a missing Accept header.
+func acceptVersions(req *http.Request) []string {
+	if req == nil || req.Header.Get("Accept") == "" {
+		return []string{""}
+	}
+
+	accepts := parseAccept(req)
+	versions := make([]string, 0, len(accepts))
+	for _, a := range accepts {
+		version := a.params["version"]
+		if m := vendorVersion.FindStringSubmatch(a.mediaType); m != nil {
+			version = m[1]
+		}
+		versions = append(versions, normalizeVersion(version))
+	}
+
+	return versions
+}
+
+// normalizeVersion removes the prefix v, so v2 and 2 are the same version
+func normalizeVersion(version string) string {
+	return strings.TrimPrefix(strings.ToLower(version), "v")
+}
+
+// versioned reports whether one of the routes is bound to a version
+func versioned(routes []RouteInterface) bool {
+	for _, route := range routes {
+		if versionOf(route) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateVersion returns the routes of the version the request prefers.
+// A request without version gets the routes without version or else the routes of
+// the default version of the router. It returns nil if no version is acceptable.
+func (r *Router) negotiateVersion(req *http.Request, routes []RouteInterface) []RouteInterface {
+	for _, version := range acceptVersions(req) {
+		chosen := routesOfVersion(routes, version)
+		if len(chosen) == 0 && version == "" && r.DefaultVersion != "" {
+			chosen = routesOfVersion(routes, normalizeVersion(r.DefaultVersion))
+		}
+
+		if len(chosen) > 0 {
+			return chosen
+		}
+	}
+
+	return nil
+}
+
+func routesOfVersion(routes []RouteInterface, version string) []RouteInterface {
+	chosen := make([]RouteInterface, 0, len(routes))
+	for _, route := range routes {
+		if normalizeVersion(versionOf(route)) == version {
+			chosen = append(chosen, route)
+		}
+	}
+	return chosen
+}
@@ -0,0 +1,60 @@
+package trixie
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterVersions(t *testing.T) {
+	router := Classic()
+	router.DefaultVersion = "v1"
+
+	router.Get("/items", namedHandler("v1")).(*Route).SetVersion("v1")
+	router.Get("/items", namedHandler("v2")).(*Route).SetVersion("2")
+	router.Post("/items", namedHandler("post"))
+	router.Get("/users", namedHandler("users"))
+	router.Get("/orders", namedHandler("orders")).(*Route).SetVersion("v2").(*Route).Produces("application/json")
+
+	testCases := []struct {
+		method     string
+		url        string
+		accept     string
+		statusCode int
+		body       string
+		vary       string
+	}{
+		{method: http.MethodGet, url: "/items", statusCode: http.StatusOK, body: "v1", vary: "Accept"},
+		{method: http.MethodGet, url: "/items", accept: "application/vnd.acme.v2+json", statusCode: http.StatusOK, body: "v2", vary: "Accept"},
+		{method: http.MethodGet, url: "/items", accept: "application/json; version=1", statusCode: http.StatusOK, body: "v1", vary: "Accept"},
+		{method: http.MethodGet, url: "/items", accept: "application/vnd.acme.v3+json, application/vnd.acme.v2+json;q=0.5", statusCode: http.StatusOK, body: "v2", vary: "Accept"},
+		{method: http.MethodGet, url: "/items", accept: "application/vnd.acme.v3+json, */*;q=0.1", statusCode: http.StatusOK, body: "v1", vary: "Accept"},
+		{method: http.MethodGet, url: "/items", accept: "application/vnd.acme.v3+json", statusCode: http.StatusNotAcceptable, vary: "Accept"},
+		{method: http.MethodPost, url: "/items", accept: "application/vnd.acme.v3+json", statusCode: http.StatusOK, body: "post", vary: "Accept"},
+		{method: http.MethodGet, url: "/users", accept: "application/vnd.acme.v3+json", statusCode: http.StatusOK, body: "users"},
+		{method: http.MethodGet, url: "/orders", accept: "application/vnd.acme.v2+json", statusCode: http.StatusOK, body: "orders", vary: "Accept"},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(testCase.method, testCase.url, nil)
+		if testCase.accept != "" {
+			req.Header.Set("Accept", testCase.accept)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != testCase.statusCode {
+			t.Errorf("%s %s (%s): Unexpected status code (Expected: %d, Actual: %d)", testCase.method, testCase.url, testCase.accept, testCase.statusCode, rec.Code)
+			continue
+		}
+
+		if testCase.body != "" && rec.Body.String() != testCase.body {
+			t.Errorf("%s %s (%s): Unexpected body (Expected: %s, Actual: %s)", testCase.method, testCase.url, testCase.accept, testCase.body, rec.Body.String())
+		}
+
+		if vary := rec.Header().Get("Vary"); vary != testCase.vary {
+			t.Errorf("%s %s (%s): Unexpected Vary header (Expected: %q, Actual: %q)", testCase.method, testCase.url, testCase.accept, testCase.vary, vary)
+		}
+	}
+}